@@ -29,3 +29,12 @@ var (
 	O3_CROSSCHAIN_ID       = uint64(10)
 	OK_CROSSCHAIN_ID 	   = uint64(90)
 )
+
+// MIN_BLOCKS_TO_WAIT denotes the least side chain confirmations allowed to register side chain on poly,
+// it's also the default one, chain config may raise it but never lower it.
+var MIN_BLOCKS_TO_WAIT = map[uint64]uint64{
+	ETHEREUM_CROSSCHAIN_ID: 12,
+	BSC_CROSSCHAIN_ID:      15,
+	HECO_CROSSCHAIN_ID:     21,
+	OK_CROSSCHAIN_ID:       1,
+}
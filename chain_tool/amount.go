@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"poly-bridge/chainsdk"
+	"poly-bridge/utils/math"
+
+	"github.com/urfave/cli"
+)
+
+// nativeUnits are the units of native coin of ethereum like chains.
+var nativeUnits = map[string]uint8{
+	"wei":    0,
+	"kwei":   3,
+	"mwei":   6,
+	"gwei":   9,
+	"szabo":  12,
+	"finney": 15,
+	"ether":  18,
+}
+
+var amountPattern = regexp.MustCompile(`^(\S*?)\s*([A-Za-z][A-Za-z0-9]*)?$`)
+
+// parseAmount converts amount with unit to minimal unit of `token`, e.g: 1.5ether, 20gwei or 100 USDT.
+// units of native coin are allowed if token is nil or native coin, otherwise the token symbol is the
+// only unit. amount without unit is in `plainDecimals`, e.g: 0 for wei.
+func parseAmount(value string, token *chainsdk.ERC20Meta, plainDecimals uint8) (*big.Int, error) {
+	match := amountPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil || match[1] == "" {
+		return nil, fmt.Errorf("invalid amount %q", value)
+	}
+
+	unit, decimals := match[2], plainDecimals
+	native := token == nil || token.Token == chainsdk.NativeFeeToken
+	if unit != "" {
+		var ok bool
+		if native {
+			decimals, ok = nativeUnits[strings.ToLower(unit)]
+		} else if ok = strings.EqualFold(unit, token.Symbol); ok {
+			decimals = token.Decimals
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown unit %q in amount %q", unit, value)
+		}
+	}
+
+	amount, err := math.ParseTokenAmount(match[1], decimals)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Sign() < 0 {
+		return nil, fmt.Errorf("negative amount %q", value)
+	}
+	return amount.Raw(), nil
+}
+
+// formatAmount formats amount in minimal unit as human readable amount of token with `decimals`.
+func formatAmount(amount *big.Int, decimals uint8) string {
+	if amount == nil {
+		return "-"
+	}
+	return math.NewTokenAmount(amount, decimals).String()
+}
+
+// formatNative formats native coin amount in ether, which can be parsed back by parseAmount.
+func formatNative(amount *big.Int) string {
+	return formatAmount(amount, chainsdk.NativeDecimals) + " ether"
+}
+
+// formatToken formats token amount with symbol, which can be parsed back by parseAmount.
+func formatToken(amount *big.Int, token *chainsdk.ERC20Meta) string {
+	if token.Token == chainsdk.NativeFeeToken {
+		return formatNative(amount)
+	}
+	return formatAmount(amount, token.Decimals) + " " + token.Symbol
+}
+
+// flag2amount parses amount flag with parseAmount, zero amount is rejected.
+func flag2amount(ctx *cli.Context, f cli.Flag, token *chainsdk.ERC20Meta, plainDecimals uint8) (*big.Int, error) {
+	amount, err := parseAmount(flag2string(ctx, f), token, plainDecimals)
+	if err != nil {
+		return nil, err
+	}
+	if amount.Sign() == 0 {
+		return nil, fmt.Errorf("zero amount %q", flag2string(ctx, f))
+	}
+	return amount, nil
+}
@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	xecdsa "poly-bridge/utils/ecdsa"
+	"poly-bridge/utils/files"
+
+	log "github.com/astaxie/beego/logs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	bindStatusBound   = "bound"
+	bindStatusSkipped = "skipped"
+	bindStatusFailed  = "failed"
+)
+
+// tokenBinding is an item of token list, asset on source chain is bound to asset on dest chain.
+type tokenBinding struct {
+	SrcChain uint64 `json:"srcChain"`
+	SrcAsset string `json:"srcAsset"`
+	DstChain uint64 `json:"dstChain"`
+	DstAsset string `json:"dstAsset"`
+}
+
+func (b *tokenBinding) validate() error {
+	if !common.IsHexAddress(b.SrcAsset) || !common.IsHexAddress(b.DstAsset) {
+		return fmt.Errorf("invalid asset address")
+	}
+	if common.HexToAddress(b.DstAsset) == (common.Address{}) {
+		return fmt.Errorf("dest asset is empty address")
+	}
+	if b.SrcChain == b.DstChain {
+		return fmt.Errorf("source chain is the same as dest chain")
+	}
+	return nil
+}
+
+// loadTokenList reads token list in csv if the file extension is .csv, otherwise in json. the csv
+// columns are srcChain, srcAsset, dstChain and dstAsset, header line and lines started with # are ignored.
+func loadTokenList(path string) ([]*tokenBinding, error) {
+	if strings.ToLower(filepath.Ext(path)) != ".csv" {
+		list := make([]*tokenBinding, 0)
+		if err := files.ReadJsonFile(path, &list); err != nil {
+			return nil, err
+		}
+		return list, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*tokenBinding, 0, len(records))
+	for i, record := range records {
+		srcChain, err := strconv.ParseUint(record[0], 10, 64)
+		if err != nil && i == 0 {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid src chain %s", i+1, record[0])
+		}
+		dstChain, err := strconv.ParseUint(record[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid dst chain %s", i+1, record[2])
+		}
+		list = append(list, &tokenBinding{
+			SrcChain: srcChain,
+			SrcAsset: record[1],
+			DstChain: dstChain,
+			DstAsset: record[3],
+		})
+	}
+	return list, nil
+}
+
+type tokenBindingResult struct {
+	*tokenBinding
+	Status string `json:"status"`
+	TxHash string `json:"txHash,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+func (item *tokenBindingResult) set(status, reason string) {
+	item.Status, item.Reason = status, reason
+}
+
+type bindTokensReport struct {
+	ChainID uint64                `json:"chainId"`
+	Items   []*tokenBindingResult `json:"items"`
+	Bound   int                   `json:"bound"`
+	Skipped int                   `json:"skipped"`
+	Failed  int                   `json:"failed"`
+}
+
+func (r *bindTokensReport) print() {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "bind tokens of chain %d\r\n", r.ChainID)
+	for _, item := range r.Items {
+		fmt.Fprintf(&buf, "%-8s %s -> chain %d %s %s %s\r\n", item.Status, item.SrcAsset, item.DstChain,
+			item.DstAsset, item.TxHash, item.Reason)
+	}
+	fmt.Fprintf(&buf, "total %d, bound %d, skipped %d, failed %d", len(r.Items), r.Bound, r.Skipped, r.Failed)
+	log.Info(buf.String())
+}
+
+// bindTokens binds items in list whose source chain is current chain, pairs already bound are skipped
+// and the txs are sent with sequential nonces before waiting them packed.
+func bindTokens(ctx context.Context, list []*tokenBinding) (*bindTokensReport, error) {
+	report := &bindTokensReport{ChainID: cc.SideChainID, Items: make([]*tokenBindingResult, 0, len(list))}
+	pending := make([]*tokenBindingResult, 0, len(list))
+	seen := make(map[string]bool)
+	for _, binding := range list {
+		item := &tokenBindingResult{tokenBinding: binding}
+		report.Items = append(report.Items, item)
+		key := fmt.Sprintf("%s-%d", strings.ToLower(binding.SrcAsset), binding.DstChain)
+		if binding.SrcChain != cc.SideChainID {
+			item.set(bindStatusSkipped, fmt.Sprintf("source chain is not %d", cc.SideChainID))
+		} else if err := binding.validate(); err != nil {
+			item.set(bindStatusFailed, err.Error())
+		} else if seen[key] {
+			item.set(bindStatusFailed, "duplicated in token list")
+		} else {
+			seen[key] = true
+			pending = append(pending, item)
+		}
+	}
+
+	proxy := common.HexToAddress(cc.LockProxy)
+	if len(pending) > 0 {
+		assets := make([]common.Address, len(pending))
+		dstChainIds := make([]uint64, len(pending))
+		for i, item := range pending {
+			assets[i], dstChainIds[i] = common.HexToAddress(item.SrcAsset), item.DstChain
+		}
+		bound, err := sdk.GetAssetHashMaps(ctx, common.HexToAddress(cc.Multicall), proxy, assets, dstChainIds)
+		if err != nil {
+			return nil, err
+		}
+		unbound := pending[:0]
+		for i, item := range pending {
+			if len(bound[i]) > 0 && common.BytesToAddress(bound[i]) == common.HexToAddress(item.DstAsset) {
+				item.set(bindStatusSkipped, "already bound")
+			} else {
+				unbound = append(unbound, item)
+			}
+		}
+		pending = unbound
+	}
+
+	if len(pending) > 0 {
+		owner := xecdsa.Key2address(adm)
+		nonce, err := sdk.NonceAt(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		sent := make([]*tokenBindingResult, 0, len(pending))
+		for i, item := range pending {
+			hash, err := sdk.SendBindERC20Asset(adm, proxy, common.HexToAddress(item.SrcAsset),
+				common.HexToAddress(item.DstAsset), item.DstChain, nonce)
+			if err != nil {
+				item.set(bindStatusFailed, err.Error())
+				// the failed tx may still reach the node, e.g: timeout, so nonce is taken from the node again
+				if nonce, err = sdk.NonceAt(ctx, owner); err != nil {
+					for _, rest := range pending[i+1:] {
+						rest.set(bindStatusFailed, fmt.Sprintf("not sent, get nonce failed, err: %v", err))
+					}
+					break
+				}
+				continue
+			}
+			log.Info("bind %s to chain %d %s, nonce %d, txhash %s", item.SrcAsset, item.DstChain, item.DstAsset,
+				nonce, hash.Hex())
+			item.TxHash = hash.Hex()
+			sent = append(sent, item)
+			nonce++
+		}
+		waitBindTokens(ctx, sent)
+	}
+
+	for _, item := range report.Items {
+		switch item.Status {
+		case bindStatusBound:
+			report.Bound++
+		case bindStatusSkipped:
+			report.Skipped++
+		default:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// waitBindTokens waits receipts of the txs sent until they're all packed or ctx is done.
+func waitBindTokens(ctx context.Context, sent []*tokenBindingResult) {
+	for len(sent) > 0 {
+		hashes := make([]common.Hash, len(sent))
+		for i, item := range sent {
+			hashes[i] = common.HexToHash(item.TxHash)
+		}
+		receipts, err := sdk.BatchTransactionReceipt(ctx, hashes)
+		if err != nil {
+			log.Warn("get receipts of bind txs failed, err: %v", err)
+		}
+		unpacked := sent[:0]
+		for i, item := range sent {
+			switch {
+			case err != nil || receipts[i] == nil:
+				unpacked = append(unpacked, item)
+			case receipts[i].Status == types.ReceiptStatusSuccessful:
+				item.set(bindStatusBound, "")
+			default:
+				item.set(bindStatusFailed, fmt.Sprintf("tx failed at height %d", receipts[i].BlockNumber))
+			}
+		}
+		sent = unpacked
+		if len(sent) == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			for _, item := range sent {
+				item.set(bindStatusFailed, "tx is not packed in time")
+			}
+			return
+		case <-time.After(trackInterval):
+		}
+	}
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+
+	"poly-bridge/basedef"
+	"poly-bridge/chainsdk"
+
+	log "github.com/astaxie/beego/logs"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// allChainConfigs returns configs of side chains which have lock proxy deployed.
+func allChainConfigs() []*ChainConfig {
+	list := make([]*ChainConfig, 0, 4)
+	for _, c := range []*ChainConfig{cfg.Ethereum, cfg.Bsc, cfg.Heco, cfg.Ok} {
+		if c != nil && c.LockProxy != "" {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// formatBinding formats the hash bound in lock proxy as address of chain `chainId`.
+func formatBinding(chainId uint64, hash []byte) string {
+	if len(hash) == 0 {
+		return "not bound"
+	}
+	return basedef.Hash2Address(chainId, hex.EncodeToString(hash))
+}
+
+// chainSdk reuses the global sdk for current chain.
+func chainSdk(c *ChainConfig) (*chainsdk.EthereumSdk, func(), error) {
+	if c == cc {
+		return sdk, func() {}, nil
+	}
+	s, err := chainsdk.NewEthereumSdk(c.RPC)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s, s.Close, nil
+}
+
+func showProxyBindings(ctx context.Context, chains []*ChainConfig) error {
+	for _, src := range chains {
+		dstChainIds := make([]uint64, 0, len(chains))
+		for _, dst := range chains {
+			if dst != src {
+				dstChainIds = append(dstChainIds, dst.SideChainID)
+			}
+		}
+		s, release, err := chainSdk(src)
+		if err != nil {
+			return err
+		}
+		hashes, err := s.GetProxyHashMaps(ctx, common.HexToAddress(src.Multicall),
+			common.HexToAddress(src.LockProxy), dstChainIds)
+		release()
+		if err != nil {
+			return err
+		}
+		for i, dstChainId := range dstChainIds {
+			log.Info("chain %d proxy %s -> chain %d proxy %s", src.SideChainID, src.LockProxy,
+				dstChainId, formatBinding(dstChainId, hashes[i]))
+		}
+	}
+	return nil
+}
+
+func showAssetBindings(ctx context.Context, chains []*ChainConfig, assets []common.Address) error {
+	var (
+		fromAssets  []common.Address
+		dstChainIds []uint64
+	)
+	for _, asset := range assets {
+		for _, dst := range chains {
+			if dst != cc {
+				fromAssets = append(fromAssets, asset)
+				dstChainIds = append(dstChainIds, dst.SideChainID)
+			}
+		}
+	}
+	hashes, err := sdk.GetAssetHashMaps(ctx, common.HexToAddress(cc.Multicall), common.HexToAddress(cc.LockProxy),
+		fromAssets, dstChainIds)
+	if err != nil {
+		return err
+	}
+	for i, asset := range fromAssets {
+		log.Info("chain %d asset %s -> chain %d asset %s", cc.SideChainID, asset.Hex(),
+			dstChainIds[i], formatBinding(dstChainIds[i], hashes[i]))
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"poly-bridge/chainsdk"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// assetKey is an asset bound to dest chain in lock proxy.
+type assetKey struct {
+	asset    common.Address
+	dstChain uint64
+}
+
+// chainBindings holds bindings and token metas read from lock proxy of a chain.
+type chainBindings struct {
+	cfg     *ChainConfig
+	proxies map[uint64][]byte
+	assets  map[assetKey][]byte
+	metas   map[common.Address]*chainsdk.ERC20Meta
+}
+
+func newChainBindings(c *ChainConfig) *chainBindings {
+	return &chainBindings{
+		cfg:     c,
+		proxies: make(map[uint64][]byte),
+		assets:  make(map[assetKey][]byte),
+		metas:   make(map[common.Address]*chainsdk.ERC20Meta),
+	}
+}
+
+// load reads the bindings and metas which keys are collected in maps.
+func (b *chainBindings) load(ctx context.Context) error {
+	s, release, err := chainSdk(b.cfg)
+	if err != nil {
+		return err
+	}
+	defer release()
+	multicall, proxy := common.HexToAddress(b.cfg.Multicall), common.HexToAddress(b.cfg.LockProxy)
+
+	dstChainIds := make([]uint64, 0, len(b.proxies))
+	for chainId := range b.proxies {
+		dstChainIds = append(dstChainIds, chainId)
+	}
+	proxies, err := s.GetProxyHashMaps(ctx, multicall, proxy, dstChainIds)
+	if err != nil {
+		return err
+	}
+	for i, chainId := range dstChainIds {
+		b.proxies[chainId] = proxies[i]
+	}
+
+	keys := make([]assetKey, 0, len(b.assets))
+	assets, chainIds := make([]common.Address, 0, len(b.assets)), make([]uint64, 0, len(b.assets))
+	for key := range b.assets {
+		keys = append(keys, key)
+		assets, chainIds = append(assets, key.asset), append(chainIds, key.dstChain)
+	}
+	hashes, err := s.GetAssetHashMaps(ctx, multicall, proxy, assets, chainIds)
+	if err != nil {
+		return err
+	}
+	for i, key := range keys {
+		b.assets[key] = hashes[i]
+	}
+
+	tokens := make([]common.Address, 0, len(b.metas))
+	for token := range b.metas {
+		tokens = append(tokens, token)
+	}
+	metas, err := s.GetERC20Metas(ctx, multicall, tokens)
+	if err != nil {
+		return err
+	}
+	for _, meta := range metas {
+		b.metas[meta.Token] = meta
+	}
+	return nil
+}
+
+// checkBinding compares the hash bound in lock proxy with the expected address.
+func checkBinding(name string, chainId uint64, bound []byte, expected common.Address) string {
+	switch {
+	case len(bound) == 0:
+		return fmt.Sprintf("%s is missing", name)
+	case common.BytesToAddress(bound) == expected:
+		return ""
+	case len(bytes.Trim(bound, "\x00")) == 0:
+		return fmt.Sprintf("%s is zero address", name)
+	}
+	return fmt.Sprintf("%s is asymmetric, bound to %s", name, formatBinding(chainId, bound))
+}
+
+type bindingCheckResult struct {
+	*tokenBinding
+	SrcSymbol   string   `json:"srcSymbol"`
+	SrcDecimals uint8    `json:"srcDecimals"`
+	DstSymbol   string   `json:"dstSymbol"`
+	DstDecimals uint8    `json:"dstDecimals"`
+	Issues      []string `json:"issues"`
+}
+
+type checkBindingsReport struct {
+	Items  []*bindingCheckResult `json:"items"`
+	Issues int                   `json:"issues"`
+}
+
+func (r *checkBindingsReport) table() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SRC CHAIN\tSRC ASSET\tSYMBOL\tDECIMALS\tDST CHAIN\tDST ASSET\tSYMBOL\tDECIMALS\tISSUES")
+	for _, item := range r.Items {
+		issues := "ok"
+		if len(item.Issues) > 0 {
+			issues = fmt.Sprint(item.Issues)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%d\t%s\t%s\t%d\t%s\n", item.SrcChain, item.SrcAsset, item.SrcSymbol,
+			item.SrcDecimals, item.DstChain, item.DstAsset, item.DstSymbol, item.DstDecimals, issues)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// checkBindings verifies that lock proxies of both side in token list pairs are bound to each other,
+// the assets are bound symmetrically and the decimals of paired assets are the same.
+func checkBindings(ctx context.Context, list []*tokenBinding) (*checkBindingsReport, error) {
+	chains := make(map[uint64]*chainBindings)
+	for _, c := range allChainConfigs() {
+		chains[c.SideChainID] = newChainBindings(c)
+	}
+
+	report := &checkBindingsReport{Items: make([]*bindingCheckResult, 0, len(list))}
+	for _, binding := range list {
+		item := &bindingCheckResult{tokenBinding: binding, Issues: make([]string, 0)}
+		report.Items = append(report.Items, item)
+		src, dst := chains[binding.SrcChain], chains[binding.DstChain]
+		if err := binding.validate(); err != nil {
+			item.Issues = append(item.Issues, err.Error())
+		} else if src == nil || dst == nil {
+			item.Issues = append(item.Issues, "chain is not configured or lock proxy is not deployed")
+		} else {
+			srcAsset, dstAsset := common.HexToAddress(binding.SrcAsset), common.HexToAddress(binding.DstAsset)
+			src.proxies[binding.DstChain], dst.proxies[binding.SrcChain] = nil, nil
+			src.assets[assetKey{srcAsset, binding.DstChain}] = nil
+			dst.assets[assetKey{dstAsset, binding.SrcChain}] = nil
+			src.metas[srcAsset], dst.metas[dstAsset] = nil, nil
+		}
+	}
+	for _, chain := range chains {
+		if len(chain.assets) == 0 {
+			continue
+		}
+		if err := chain.load(ctx); err != nil {
+			return nil, fmt.Errorf("read bindings of chain %d failed, err: %v", chain.cfg.SideChainID, err)
+		}
+	}
+
+	for _, item := range report.Items {
+		if len(item.Issues) > 0 {
+			report.Issues += len(item.Issues)
+			continue
+		}
+		src, dst := chains[item.SrcChain], chains[item.DstChain]
+		srcAsset, dstAsset := common.HexToAddress(item.SrcAsset), common.HexToAddress(item.DstAsset)
+		checks := []string{
+			checkBinding(fmt.Sprintf("proxy of chain %d to chain %d", item.SrcChain, item.DstChain), item.DstChain,
+				src.proxies[item.DstChain], common.HexToAddress(dst.cfg.LockProxy)),
+			checkBinding(fmt.Sprintf("proxy of chain %d to chain %d", item.DstChain, item.SrcChain), item.SrcChain,
+				dst.proxies[item.SrcChain], common.HexToAddress(src.cfg.LockProxy)),
+			checkBinding("asset on source chain", item.DstChain,
+				src.assets[assetKey{srcAsset, item.DstChain}], dstAsset),
+			checkBinding("asset on dest chain", item.SrcChain,
+				dst.assets[assetKey{dstAsset, item.SrcChain}], srcAsset),
+		}
+		srcMeta, dstMeta := src.metas[srcAsset], dst.metas[dstAsset]
+		item.SrcSymbol, item.SrcDecimals = srcMeta.Symbol, srcMeta.Decimals
+		item.DstSymbol, item.DstDecimals = dstMeta.Symbol, dstMeta.Decimals
+		if srcMeta.Err != nil {
+			checks = append(checks, srcMeta.Err.Error())
+		}
+		if dstMeta.Err != nil {
+			checks = append(checks, dstMeta.Err.Error())
+		}
+		if srcMeta.Err == nil && dstMeta.Err == nil && srcMeta.Decimals != dstMeta.Decimals {
+			checks = append(checks, fmt.Sprintf("decimals mismatch %d != %d", srcMeta.Decimals, dstMeta.Decimals))
+		}
+		for _, issue := range checks {
+			if issue != "" {
+				item.Issues = append(item.Issues, issue)
+			}
+		}
+		report.Issues += len(item.Issues)
+	}
+	return report, nil
+}
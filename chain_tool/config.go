@@ -17,6 +17,8 @@
 
 package main
 
+import "encoding/json"
+
 type Config struct {
 	Ethereum *ChainConfig
 	Bsc      *ChainConfig
@@ -42,6 +44,21 @@ type ChainConfig struct {
 	ECCM      string
 	CCMP      string
 	LockProxy string
+	// --create2部署eccd, eccm, ccmp时使用的salt, key为合约名, 32字节hex直接使用, 否则使用其keccak256,
+	// 为空时使用合约名. eccm和ccmp的构造参数包含chainId和eccd/eccm地址, 各链地址可能不同.
+	Create2Salt map[string]string
+	// Multicall2合约地址, 为空时批量查询使用json rpc batch.
+	Multicall string
+	// 同步创世区块头时的header格式, 为空或ethereum时重新编码并校验hash, raw时直接使用节点返回的原始json,
+	// 适用于header包含额外字段或hash计算方式不同的侧链.
+	HeaderProfile string
+
+	// 侧链在poly上的注册参数, 为空时使用默认值. BlocksToWait不能小于MinBlocksToWait和basedef中各网络的最小值,
+	// MinBlocksToWait只能提高该最小值, 除非使用--force.
+	BlocksToWait    uint64
+	MinBlocksToWait uint64
+	Router          uint64
+	ExtraInfo       json.RawMessage
 
 	NFTLockProxy string
 	NFTWrap      string
@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"poly-bridge/chainsdk"
+	xecdsa "poly-bridge/utils/ecdsa"
+
+	log "github.com/astaxie/beego/logs"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// create2Salt returns salt of contract configured, the contract name is used if it's not set.
+func create2Salt(name string) common.Hash {
+	salt, ok := cc.Create2Salt[name]
+	if !ok || salt == "" {
+		salt = name
+	}
+	return chainsdk.Create2Salt(salt)
+}
+
+// deployCreate2 deploys contract through create2 factory, the address is shown before tx sent and
+// nothing is sent if the contract exists already, so it's safe to run again.
+func deployCreate2(name, abiStr, bin string, args ...interface{}) (common.Address, error) {
+	initCode, err := chainsdk.DeployData(abiStr, bin, args...)
+	if err != nil {
+		return chainsdk.EmptyAddress, err
+	}
+	salt := create2Salt(name)
+	addr := chainsdk.Create2Address(xecdsa.Key2address(adm), salt, initCode)
+	log.Info("%s of chain %d will be deployed at %s through create2 factory %s, salt %s",
+		name, cc.SideChainID, addr.Hex(), chainsdk.Create2Factory.Hex(), salt.Hex())
+
+	addr, deployed, err := sdk.DeployCreate2(adm, salt, initCode)
+	if err != nil {
+		return addr, fmt.Errorf("create2 deploy %s err: %v", name, err)
+	}
+	if !deployed {
+		log.Info("%s of chain %d is already deployed at %s", name, cc.SideChainID, addr.Hex())
+	}
+	return addr, nil
+}
@@ -0,0 +1,208 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"text/tabwriter"
+
+	"poly-bridge/chainsdk"
+	"poly-bridge/go_abi/eccd_abi"
+	"poly-bridge/go_abi/eccm_abi"
+	"poly-bridge/go_abi/eccmp_abi"
+	"poly-bridge/go_abi/lock_proxy_abi"
+	xecdsa "poly-bridge/utils/ecdsa"
+
+	log "github.com/astaxie/beego/logs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// deployStep is a tx of chain deployment, the gas limit used by this tool is taken if it can't be
+// estimated, e.g: the contract called is not deployed yet.
+type deployStep struct {
+	name     string
+	count    int
+	gasLimit uint64
+	estimate func(ctx context.Context) (uint64, error)
+}
+
+type deployCost struct {
+	Name   string
+	Count  int
+	Gas    uint64
+	Reason string
+}
+
+// contractOrPredicted returns the configured contract, or the address it will be deployed at by
+// admin with nonce, the nonce is taken by the deploy tx and advanced only in the latter case.
+func contractOrPredicted(configured string, admin common.Address, nonce *uint64) common.Address {
+	if common.IsHexAddress(configured) && common.HexToAddress(configured) != chainsdk.EmptyAddress {
+		return common.HexToAddress(configured)
+	}
+	addr := crypto.CreateAddress(admin, *nonce)
+	*nonce++
+	return addr
+}
+
+func estimateDeploy(admin common.Address, abiStr, bin string, args ...interface{}) func(context.Context) (uint64, error) {
+	return func(ctx context.Context) (uint64, error) {
+		return sdk.EstimateDeployGas(ctx, admin, abiStr, bin, args...)
+	}
+}
+
+func estimateCall(
+	admin, contract common.Address,
+	abiStr, method string,
+	args ...interface{},
+) func(context.Context) (uint64, error) {
+
+	return func(ctx context.Context) (uint64, error) {
+		code, err := sdk.CodeAt(ctx, contract)
+		if err != nil {
+			return 0, err
+		}
+		if len(code) == 0 {
+			return 0, fmt.Errorf("%s is not deployed", contract.Hex())
+		}
+		return sdk.EstimateCallGas(ctx, admin, contract, abiStr, method, args...)
+	}
+}
+
+// deploySteps lists txs to deploy cross chain contracts on current chain and bind it to other
+// configured chains, the contracts not deployed yet are at the addresses predicted from admin nonce.
+func deploySteps(ctx context.Context, admin common.Address) ([]*deployStep, error) {
+	nonce, err := sdk.NonceAt(ctx, admin)
+	if err != nil {
+		return nil, err
+	}
+	eccd := contractOrPredicted(cc.ECCD, admin, &nonce)
+	eccm := contractOrPredicted(cc.ECCM, admin, &nonce)
+	ccmp := contractOrPredicted(cc.CCMP, admin, &nonce)
+	proxy := contractOrPredicted(cc.LockProxy, admin, &nonce)
+
+	initGenesis := func(ctx context.Context) (uint64, error) {
+		polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+		if err != nil {
+			return 0, err
+		}
+		header, bookkeepers, err := polyGenesisHeader(polySdk)
+		if err != nil {
+			return 0, err
+		}
+		return estimateCall(admin, eccm, eccm_abi.EthCrossChainManagerABI, "initGenesisBlock",
+			header, bookkeepers)(ctx)
+	}
+
+	steps := []*deployStep{
+		{"deploy eccd", 1, chainsdk.DefaultDeployGasLimit,
+			estimateDeploy(admin, eccd_abi.EthCrossChainDataABI, eccd_abi.EthCrossChainDataBin)},
+		{"deploy eccm", 1, chainsdk.DefaultDeployGasLimit,
+			estimateDeploy(admin, eccm_abi.EthCrossChainManagerABI, eccm_abi.EthCrossChainManagerBin, eccd, cc.SideChainID)},
+		{"deploy ccmp", 1, chainsdk.DefaultDeployGasLimit,
+			estimateDeploy(admin, eccmp_abi.EthCrossChainManagerProxyABI, eccmp_abi.EthCrossChainManagerProxyBin, eccm)},
+		{"deploy lock proxy", 1, chainsdk.DefaultDeployGasLimit,
+			estimateDeploy(admin, lock_proxy_abi.LockProxyABI, lock_proxy_abi.LockProxyBin)},
+		{"transfer eccd ownership", 1, chainsdk.DefaultGasLimit,
+			estimateCall(admin, eccd, eccd_abi.EthCrossChainDataABI, "transferOwnership", eccm)},
+		{"init genesis block", 1, chainsdk.DefaultGasLimit, initGenesis},
+		{"transfer eccm ownership", 1, chainsdk.DefaultGasLimit,
+			estimateCall(admin, eccm, eccm_abi.EthCrossChainManagerABI, "transferOwnership", ccmp)},
+		{"set lock proxy manager", 1, chainsdk.DefaultGasLimit,
+			estimateCall(admin, proxy, lock_proxy_abi.LockProxyABI, "setManagerProxy", ccmp)},
+	}
+
+	// bind lock proxy and native asset to every other chain
+	for _, c := range allChainConfigs() {
+		if c == cc {
+			continue
+		}
+		dstProxy := common.HexToAddress(c.LockProxy)
+		steps = append(steps,
+			&deployStep{fmt.Sprintf("bind proxy to chain %d", c.SideChainID), 1, chainsdk.DefaultGasLimit,
+				estimateCall(admin, proxy, lock_proxy_abi.LockProxyABI, "bindProxyHash", c.SideChainID, dstProxy.Bytes())},
+			&deployStep{fmt.Sprintf("bind asset to chain %d", c.SideChainID), 1, chainsdk.DefaultGasLimit,
+				estimateCall(admin, proxy, lock_proxy_abi.LockProxyABI, "bindAssetHash", chainsdk.NativeFeeToken,
+					c.SideChainID, chainsdk.NativeFeeToken.Bytes())},
+		)
+	}
+	return steps, nil
+}
+
+// estimateDeployCost estimates gas of deploy steps and compares the cost at current gas price with
+// balance of admin.
+func estimateDeployCost(ctx context.Context) error {
+	admin := xecdsa.Key2address(adm)
+	steps, err := deploySteps(ctx, admin)
+	if err != nil {
+		return err
+	}
+	gasPrice, err := sdk.SuggestGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	balance, err := sdk.GetNativeBalance(admin)
+	if err != nil {
+		return err
+	}
+
+	var (
+		buf      bytes.Buffer
+		totalGas uint64
+		maxLimit uint64
+	)
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tCOUNT\tGAS\tCOST\tNOTE")
+	for _, step := range steps {
+		item := &deployCost{Name: step.name, Count: step.count, Gas: step.gasLimit}
+		if gas, err := step.estimate(ctx); err != nil {
+			item.Reason = fmt.Sprintf("gas limit used, %v", err)
+		} else {
+			item.Gas = gas
+		}
+		gas := item.Gas * uint64(item.Count)
+		totalGas += gas
+		if step.gasLimit > maxLimit {
+			maxLimit = step.gasLimit
+		}
+		cost := new(big.Int).Mul(new(big.Int).SetUint64(gas), gasPrice)
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\n", item.Name, item.Count, item.Gas, formatNative(cost), item.Reason)
+	}
+	w.Flush()
+
+	total := new(big.Int).Mul(new(big.Int).SetUint64(totalGas), gasPrice)
+	// the node requires balance for gas limit * gas price of a tx before it's packed
+	reserve := new(big.Int).Mul(new(big.Int).SetUint64(maxLimit), gasPrice)
+	fmt.Fprintf(&buf, "gas price %s gwei, total gas %d, total cost %s\r\n",
+		formatAmount(gasPrice, 9), totalGas, formatNative(total))
+	fmt.Fprintf(&buf, "admin %s balance %s", admin.Hex(), formatNative(balance))
+	log.Info("deploy cost of chain %d\r\n%s", cc.SideChainID, buf.String())
+
+	if balance.Cmp(total) < 0 {
+		return fmt.Errorf("balance of admin %s is insufficient, %s more needed", admin.Hex(),
+			formatNative(new(big.Int).Sub(total, balance)))
+	}
+	if balance.Cmp(reserve) < 0 {
+		return fmt.Errorf("balance of admin %s is less than %s required by gas limit %d", admin.Hex(),
+			formatNative(reserve), maxLimit)
+	}
+	return nil
+}
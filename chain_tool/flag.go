@@ -19,6 +19,7 @@ package main
 
 import (
 	"strings"
+	"time"
 
 	"poly-bridge/basedef"
 
@@ -108,7 +109,7 @@ var (
 
 	AmountFlag = cli.StringFlag{
 		Name:  "amount",
-		Usage: "transfer amount or fee amount, can also used as approve amount",
+		Usage: "transfer amount or fee amount, can also used as approve amount. units are allowed, e.g: 1.5ether, 20gwei or 100 USDT, native amount without unit is in wei",
 		Value: "",
 	}
 
@@ -159,6 +160,62 @@ var (
 		Name: "hexfile",
 		Usage: "set ok hex file path",
 	}
+
+	NodeWalletFlag = cli.StringFlag{
+		Name:  "nodeWallet",
+		Usage: "set poly consensus node wallet `<path>`, the wallet passphrase is the same as poly keystore",
+	}
+
+	PeerFlag = cli.StringFlag{
+		Name:  "peer",
+		Usage: "set poly consensus node public key hex string",
+	}
+
+	TxHashFlag = cli.StringFlag{
+		Name:  "hash",
+		Usage: "set source chain tx hash",
+	}
+
+	PolyHeightFlag = cli.Uint64Flag{
+		Name:  "polyHeight",
+		Usage: "poly height to start searching for the cross chain tx, default is 1000 blocks before current height",
+	}
+
+	DstHeightFlag = cli.Uint64Flag{
+		Name:  "dstHeight",
+		Usage: "dest chain height to start searching for the cross chain tx, default is 5000 blocks before current height",
+	}
+
+	TimeoutFlag = cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "give up if the cross chain tx or txs sent are not finished in `<duration>`, e.g: 30m",
+		Value: 30 * time.Minute,
+	}
+
+	TokenListFlag = cli.StringFlag{
+		Name:  "tokens",
+		Usage: "set token list `<path>` in json or csv, each item contains srcChain, srcAsset, dstChain and dstAsset",
+	}
+
+	ReportFlag = cli.StringFlag{
+		Name:  "report",
+		Usage: "write report to json file `<path>` if set",
+	}
+
+	JsonFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "print report in json instead of table",
+	}
+
+	Create2Flag = cli.BoolFlag{
+		Name:  "create2",
+		Usage: "deploy contract through create2 factory with the salt in config, the factory is deployed if missing and nothing is sent if the contract exists at the predicted address",
+	}
+
+	ForceFlag = cli.BoolFlag{
+		Name:  "force",
+		Usage: "register or update side chain even if blocksToWait is less than the minimum of network or minBlocksToWait",
+	}
 )
 
 var (
@@ -188,18 +245,27 @@ var (
 		Name:   "deployECCD",
 		Usage:  "admin account deploy ethereum cross chain data contract.",
 		Action: handleCmdDeployECCDContract,
+		Flags: []cli.Flag{
+			Create2Flag,
+		},
 	}
 
 	CmdDeployECCMContract = cli.Command{
 		Name:   "deployECCM",
 		Usage:  "admin account deploy ethereum cross chain manage contract.",
 		Action: handleCmdDeployECCMContract,
+		Flags: []cli.Flag{
+			Create2Flag,
+		},
 	}
 
 	CmdDeployCCMPContract = cli.Command{
 		Name:   "deployCCMP",
 		Usage:  "admin account deploy ethereum cross chain manager proxy contract.",
 		Action: handleCmdDeployCCMPContract,
+		Flags: []cli.Flag{
+			Create2Flag,
+		},
 	}
 
 	CmdBindERC20Asset = cli.Command{
@@ -213,6 +279,73 @@ var (
 		},
 	}
 
+	CmdBindTokens = cli.Command{
+		Name:   "bindTokens",
+		Usage:  "admin account bind erc20 assets of source chain in token list to dest chains.",
+		Action: handleCmdBindTokens,
+		Flags: []cli.Flag{
+			TokenListFlag,
+			ReportFlag,
+			TimeoutFlag,
+		},
+	}
+
+	CmdBindProxy = cli.Command{
+		Name:   "bindProxy",
+		Usage:  "admin account bind lock proxy of dest chain in config.",
+		Action: handleCmdBindProxy,
+		Flags: []cli.Flag{
+			DstChainFlag,
+		},
+	}
+
+	CmdShowBindings = cli.Command{
+		Name:   "showBindings",
+		Usage:  "show lock proxies bound between configured chains, and assets (separated by comma) bound to other chains.",
+		Action: handleCmdShowBindings,
+		Flags: []cli.Flag{
+			AssetFlag,
+		},
+	}
+
+	CmdCheckBindings = cli.Command{
+		Name:   "checkBindings",
+		Usage:  "check lock proxies and assets in token list are bound to each other on all configured chains.",
+		Action: handleCmdCheckBindings,
+		Flags: []cli.Flag{
+			TokenListFlag,
+			ReportFlag,
+			JsonFlag,
+		},
+	}
+
+	CmdVerifyCode = cli.Command{
+		Name:   "verifyCode",
+		Usage:  "compare code of eccd, eccm, ccmp and lock proxy configured on all chains with the go_abi bindings, metadata hash is ignored.",
+		Action: handleCmdVerifyCode,
+		Flags: []cli.Flag{
+			ReportFlag,
+			JsonFlag,
+		},
+	}
+
+	CmdLiquidity = cli.Command{
+		Name:   "liquidity",
+		Usage:  "show balances held by lock proxies of assets in token list, and check locked against circulating supply.",
+		Action: handleCmdLiquidity,
+		Flags: []cli.Flag{
+			TokenListFlag,
+			ReportFlag,
+			JsonFlag,
+		},
+	}
+
+	CmdEstimateDeployCost = cli.Command{
+		Name:   "estimateDeployCost",
+		Usage:  "estimate native coin consumed by admin to deploy cross chain contracts and bind other chains.",
+		Action: handleCmdEstimateDeployCost,
+	}
+
 	CmdTransferECCDOwnership = cli.Command{
 		Name:   "transferECCDOwnership",
 		Usage:  "admin account transfer ethereum cross chain data contract ownership eccm contract.",
@@ -229,6 +362,9 @@ var (
 		Name:   "registerSideChain",
 		Usage:  "register side chain in poly.",
 		Action: handleCmdRegisterSideChain,
+		Flags: []cli.Flag{
+			ForceFlag,
+		},
 	}
 
 	CmdApproveSideChain = cli.Command{
@@ -237,6 +373,88 @@ var (
 		Action: handleCmdApproveSideChain,
 	}
 
+	CmdUpdateSideChain = cli.Command{
+		Name:   "updateSideChain",
+		Usage:  "update side chain eccd, router and name in poly.",
+		Action: handleCmdUpdateSideChain,
+		Flags: []cli.Flag{
+			ForceFlag,
+		},
+	}
+
+	CmdApproveUpdateSideChain = cli.Command{
+		Name:   "approveUpdateSideChain",
+		Usage:  "approve update side chain in poly.",
+		Action: handleCmdApproveUpdateSideChain,
+	}
+
+	CmdQuitSideChain = cli.Command{
+		Name:   "quitSideChain",
+		Usage:  "quit side chain from poly.",
+		Action: handleCmdQuitSideChain,
+	}
+
+	CmdApproveQuitSideChain = cli.Command{
+		Name:   "approveQuitSideChain",
+		Usage:  "approve quit side chain in poly.",
+		Action: handleCmdApproveQuitSideChain,
+	}
+
+	CmdSideChainInfo = cli.Command{
+		Name:   "sideChainInfo",
+		Usage:  "show side chain registration in poly and diff it with chain config.",
+		Action: handleCmdSideChainInfo,
+	}
+
+	CmdRegisterCandidate = cli.Command{
+		Name:   "registerCandidate",
+		Usage:  "register poly consensus node candidate with node wallet.",
+		Action: handleCmdRegisterCandidate,
+		Flags: []cli.Flag{
+			NodeWalletFlag,
+		},
+	}
+
+	CmdApproveCandidate = cli.Command{
+		Name:   "approveCandidate",
+		Usage:  "poly validators approve candidate which denoted by peer public key or node wallet.",
+		Action: handleCmdApproveCandidate,
+		Flags: []cli.Flag{
+			PeerFlag,
+			NodeWalletFlag,
+		},
+	}
+
+	CmdUnRegisterCandidate = cli.Command{
+		Name:   "unRegisterCandidate",
+		Usage:  "unregister poly consensus node candidate with node wallet.",
+		Action: handleCmdUnRegisterCandidate,
+		Flags: []cli.Flag{
+			NodeWalletFlag,
+		},
+	}
+
+	CmdQuitNode = cli.Command{
+		Name:   "quitNode",
+		Usage:  "quit poly consensus node with node wallet.",
+		Action: handleCmdQuitNode,
+		Flags: []cli.Flag{
+			NodeWalletFlag,
+		},
+	}
+
+	CmdListPeers = cli.Command{
+		Name:   "listPeers",
+		Usage:  "list poly peers with status and current consensus set.",
+		Action: handleCmdListPeers,
+	}
+
+	CmdCommitDpos = cli.Command{
+		Name:   "commitDpos",
+		Usage:  "poly validators commit dpos and show the new consensus set.",
+		Action: handleCmdCommitDpos,
+	}
+
 	CmdSyncSideChainGenesis2Poly = cli.Command{
 		Name:   "syncSideGenesis",
 		Usage:  "sync side chain genesis header to poly chain.",
@@ -265,13 +483,90 @@ var (
 
 	CmdNativeBalance = cli.Command{
 		Name:   "nativeBalance",
-		Usage:  "get native balance.",
+		Usage:  "get native balance, multiple accounts separated by comma are queried in one batch.",
 		Action: handleGetNativeBalance,
 		Flags: []cli.Flag{
 			SrcAccountFlag,
 		},
 	}
 
+	CmdTokenBalance = cli.Command{
+		Name:   "tokenBalance",
+		Usage:  "get erc20 balance of --asset, multiple accounts separated by comma are allowed.",
+		Action: handleCmdTokenBalance,
+		Flags: []cli.Flag{
+			AssetFlag,
+			SrcAccountFlag,
+		},
+	}
+
+	CmdTokenAllowance = cli.Command{
+		Name:   "tokenAllowance",
+		Usage:  "get erc20 allowance of --asset from owner --from to spender --to, default spender is lock proxy.",
+		Action: handleCmdTokenAllowance,
+		Flags: []cli.Flag{
+			AssetFlag,
+			SrcAccountFlag,
+			DstAccountFlag,
+		},
+	}
+
+	CmdTokenApprove = cli.Command{
+		Name:   "tokenApprove",
+		Usage:  "approve --amount in token unit, e.g: 1.5, of --asset to spender --to, default spender is lock proxy.",
+		Action: handleCmdTokenApprove,
+		Flags: []cli.Flag{
+			AssetFlag,
+			SrcAccountFlag,
+			DstAccountFlag,
+			AmountFlag,
+		},
+	}
+
+	CmdTokenTransfer = cli.Command{
+		Name:   "tokenTransfer",
+		Usage:  "transfer --amount in token unit, e.g: 1.5, of --asset.",
+		Action: handleCmdTokenTransfer,
+		Flags: []cli.Flag{
+			AssetFlag,
+			SrcAccountFlag,
+			DstAccountFlag,
+			AmountFlag,
+		},
+	}
+
+	CmdContractOwners = cli.Command{
+		Name:   "owners",
+		Usage:  "show owners of eccd, eccm, ccmp and lock proxy.",
+		Action: handleCmdContractOwners,
+	}
+
+	CmdTrack = cli.Command{
+		Name:   "track",
+		Usage:  "track cross chain tx from source chain to poly and dest chain.",
+		Action: handleCmdTrack,
+		Flags: []cli.Flag{
+			TxHashFlag,
+			PolyHeightFlag,
+			DstHeightFlag,
+			TimeoutFlag,
+		},
+	}
+
+	CmdSmokeTest = cli.Command{
+		Name:   "smokeTest",
+		Usage:  "lock asset on source chain with test account, and verify that it's unlocked on dest chain.",
+		Action: handleCmdSmokeTest,
+		Flags: []cli.Flag{
+			SrcAccountFlag,
+			DstAccountFlag,
+			AssetFlag,
+			AmountFlag,
+			DstChainFlag,
+			TimeoutFlag,
+		},
+	}
+
 	CmdEnv = cli.Command{
 		Name:   "env",
 		Usage:  "ensure your environment is correct",
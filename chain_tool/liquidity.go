@@ -0,0 +1,253 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"text/tabwriter"
+
+	"poly-bridge/utils/math"
+
+	log "github.com/astaxie/beego/logs"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// assetLiquidity is the balance of asset held by lock proxy, circulating supply is total supply
+// out of lock proxy, it's nil for native coin.
+type assetLiquidity struct {
+	ChainID     uint64   `json:"chainId"`
+	Asset       string   `json:"asset"`
+	Symbol      string   `json:"symbol"`
+	Decimals    uint8    `json:"decimals"`
+	Locked      *big.Int `json:"locked"`
+	TotalSupply *big.Int `json:"totalSupply"`
+	Circulating *big.Int `json:"circulating"`
+	Error       string   `json:"error,omitempty"`
+}
+
+func (l *assetLiquidity) lockedAmount() string {
+	return formatAmount(l.Locked, l.Decimals)
+}
+
+func (l *assetLiquidity) circulatingAmount() string {
+	return formatAmount(l.Circulating, l.Decimals)
+}
+
+// assetBacking flags the asset if the amount locked exceeds the sum of circulating supply of all assets
+// paired with it, since the locked asset backs the wrapped supply on every chain it's bound to.
+type assetBacking struct {
+	ChainID uint64   `json:"chainId"`
+	Asset   string   `json:"asset"`
+	Paired  []string `json:"paired"`
+	Issues  []string `json:"issues"`
+}
+
+type liquidityReport struct {
+	Assets   []*assetLiquidity `json:"assets"`
+	Backings []*assetBacking   `json:"backings"`
+	Issues   int               `json:"issues"`
+}
+
+func (r *liquidityReport) table() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHAIN\tASSET\tSYMBOL\tDECIMALS\tLOCKED\tCIRCULATING\tERROR")
+	for _, l := range r.Assets {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\t%s\n", l.ChainID, l.Asset, l.Symbol, l.Decimals,
+			l.lockedAmount(), l.circulatingAmount(), l.Error)
+	}
+	w.Flush()
+	for _, backing := range r.Backings {
+		for _, issue := range backing.Issues {
+			fmt.Fprintf(&buf, "chain %d %s: %s\r\n", backing.ChainID, backing.Asset, issue)
+		}
+	}
+	return buf.String()
+}
+
+// loadLiquidity reads balances of lock proxy and metas of assets on chain `c`.
+func loadLiquidity(ctx context.Context, c *ChainConfig, assets []common.Address) ([]*assetLiquidity, error) {
+	s, release, err := chainSdk(c)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	multicall := common.HexToAddress(c.Multicall)
+	balances, err := s.GetLockProxyBalances(ctx, multicall, common.HexToAddress(c.LockProxy), assets)
+	if err != nil {
+		return nil, err
+	}
+	metas, err := s.GetERC20Metas(ctx, multicall, assets)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*assetLiquidity, len(assets))
+	for i, meta := range metas {
+		l := &assetLiquidity{
+			ChainID:     c.SideChainID,
+			Asset:       assets[i].Hex(),
+			Symbol:      meta.Symbol,
+			Decimals:    meta.Decimals,
+			Locked:      balances[i],
+			TotalSupply: meta.TotalSupply,
+		}
+		if meta.Err != nil {
+			l.Error = meta.Err.Error()
+		} else if meta.TotalSupply != nil {
+			l.Circulating = new(big.Int).Sub(meta.TotalSupply, balances[i])
+		}
+		list[i] = l
+	}
+	return list, nil
+}
+
+// checkBacking compares the locked amount with the sum of circulating supply of paired assets, which
+// are rescaled to decimals of the locked asset since the decimals of paired assets may differ.
+func checkBacking(locked *assetLiquidity, paired []*assetLiquidity) (string, error) {
+	if locked.Error != "" {
+		return "", nil
+	}
+	lockedAmount := math.NewTokenAmount(locked.Locked, locked.Decimals)
+	circulating := math.NewTokenAmount(big.NewInt(0), locked.Decimals)
+	wrapped := 0
+	for _, l := range paired {
+		if l.Error != "" {
+			return "", nil
+		}
+		if l.Circulating == nil {
+			continue
+		}
+		amount, err := math.NewTokenAmount(l.Circulating, l.Decimals).Rescale(locked.Decimals, math.RoundUp)
+		if err != nil {
+			return "", err
+		}
+		if circulating, err = circulating.Add(amount); err != nil {
+			return "", err
+		}
+		wrapped++
+	}
+	if wrapped == 0 || lockedAmount.Cmp(circulating) <= 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("locked %s %s exceeds circulating %s on %d paired chains",
+		lockedAmount.String(), locked.Symbol, circulating.String(), wrapped), nil
+}
+
+// liquidity lists lock proxy balances of assets in token list on all configured chains, and checks
+// each asset is backed by the assets paired with it.
+func liquidity(ctx context.Context, list []*tokenBinding) (*liquidityReport, error) {
+	chains := make(map[uint64]*ChainConfig)
+	for _, c := range allChainConfigs() {
+		chains[c.SideChainID] = c
+	}
+	type chainAsset struct {
+		chainId uint64
+		asset   common.Address
+	}
+	assets := make(map[uint64][]common.Address)
+	seen := make(map[chainAsset]bool)
+	add := func(chainId uint64, asset common.Address) {
+		if key := (chainAsset{chainId, asset}); !seen[key] {
+			seen[key] = true
+			assets[chainId] = append(assets[chainId], asset)
+		}
+	}
+	routes := make([]*tokenBinding, 0, len(list))
+	for _, binding := range list {
+		if err := binding.validate(); err != nil {
+			log.Warn("ignore %s of chain %d to %s of chain %d, %v", binding.SrcAsset, binding.SrcChain,
+				binding.DstAsset, binding.DstChain, err)
+			continue
+		}
+		if chains[binding.SrcChain] == nil || chains[binding.DstChain] == nil {
+			log.Warn("ignore %s of chain %d to %s of chain %d, chain is not configured", binding.SrcAsset,
+				binding.SrcChain, binding.DstAsset, binding.DstChain)
+			continue
+		}
+		add(binding.SrcChain, common.HexToAddress(binding.SrcAsset))
+		add(binding.DstChain, common.HexToAddress(binding.DstAsset))
+		routes = append(routes, binding)
+	}
+
+	chainIds := make([]uint64, 0, len(assets))
+	for chainId := range assets {
+		chainIds = append(chainIds, chainId)
+	}
+	sort.Slice(chainIds, func(i, j int) bool { return chainIds[i] < chainIds[j] })
+
+	report := &liquidityReport{}
+	liquidities := make(map[chainAsset]*assetLiquidity)
+	for _, chainId := range chainIds {
+		items, err := loadLiquidity(ctx, chains[chainId], assets[chainId])
+		if err != nil {
+			return nil, fmt.Errorf("read liquidity of chain %d failed, err: %v", chainId, err)
+		}
+		for i, l := range items {
+			liquidities[chainAsset{chainId, assets[chainId][i]}] = l
+		}
+		report.Assets = append(report.Assets, items...)
+	}
+
+	// the assets paired in either direction, in the order of token list
+	pairs := make(map[chainAsset][]chainAsset)
+	order := make([]chainAsset, 0, len(liquidities))
+	pair := func(from, to chainAsset) {
+		if _, ok := pairs[from]; !ok {
+			order = append(order, from)
+		}
+		for _, key := range pairs[from] {
+			if key == to {
+				return
+			}
+		}
+		pairs[from] = append(pairs[from], to)
+	}
+	for _, binding := range routes {
+		src := chainAsset{binding.SrcChain, common.HexToAddress(binding.SrcAsset)}
+		dst := chainAsset{binding.DstChain, common.HexToAddress(binding.DstAsset)}
+		pair(src, dst)
+		pair(dst, src)
+	}
+	for _, key := range order {
+		backing := &assetBacking{
+			ChainID: key.chainId,
+			Asset:   key.asset.Hex(),
+			Paired:  make([]string, 0, len(pairs[key])),
+			Issues:  make([]string, 0),
+		}
+		paired := make([]*assetLiquidity, 0, len(pairs[key]))
+		for _, other := range pairs[key] {
+			backing.Paired = append(backing.Paired, fmt.Sprintf("%d:%s", other.chainId, other.asset.Hex()))
+			paired = append(paired, liquidities[other])
+		}
+		issue, err := checkBacking(liquidities[key], paired)
+		if err != nil {
+			return nil, fmt.Errorf("check backing of %s on chain %d failed, err: %v", backing.Asset, backing.ChainID, err)
+		}
+		if issue != "" {
+			backing.Issues = append(backing.Issues, issue)
+		}
+		report.Issues += len(backing.Issues)
+		report.Backings = append(report.Backings, backing)
+	}
+	return report, nil
+}
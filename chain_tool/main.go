@@ -18,6 +18,7 @@
 package main
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
@@ -25,17 +26,20 @@ import (
 	"os"
 	"poly-bridge/basedef"
 	"poly-bridge/chainsdk"
+	"poly-bridge/go_abi/eccd_abi"
+	"poly-bridge/go_abi/eccm_abi"
+	"poly-bridge/go_abi/eccmp_abi"
 	xecdsa "poly-bridge/utils/ecdsa"
 	"poly-bridge/utils/files"
 	"poly-bridge/utils/leveldb"
-	"poly-bridge/utils/math"
 	"poly-bridge/utils/wallet"
 	"runtime"
+	"strings"
+	"time"
 
 	log "github.com/astaxie/beego/logs"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/polynetwork/poly/native/service/header_sync/bsc"
-	polyutils "github.com/polynetwork/poly/native/service/utils"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/urfave/cli"
 )
 
@@ -85,15 +89,40 @@ func setupApp() *cli.App {
 		CmdDeployECCDContract,
 		CmdDeployECCMContract,
 		CmdDeployCCMPContract,
+		CmdEstimateDeployCost,
 		CmdBindERC20Asset,
+		CmdBindTokens,
+		CmdBindProxy,
+		CmdShowBindings,
+		CmdCheckBindings,
+		CmdLiquidity,
+		CmdVerifyCode,
 		CmdTransferECCDOwnership,
 		CmdTransferECCMOwnership,
 		CmdRegisterSideChain,
 		CmdApproveSideChain,
+		CmdUpdateSideChain,
+		CmdApproveUpdateSideChain,
+		CmdQuitSideChain,
+		CmdApproveQuitSideChain,
+		CmdSideChainInfo,
+		CmdRegisterCandidate,
+		CmdApproveCandidate,
+		CmdUnRegisterCandidate,
+		CmdQuitNode,
+		CmdListPeers,
+		CmdCommitDpos,
 		CmdSyncSideChainGenesis2Poly,
 		CmdSyncPolyGenesis2SideChain,
 		CmdNativeBalance,
 		CmdNativeTransfer,
+		CmdTokenBalance,
+		CmdTokenAllowance,
+		CmdTokenApprove,
+		CmdTokenTransfer,
+		CmdContractOwners,
+		CmdTrack,
+		CmdSmokeTest,
 		CmdEnv,
 	}
 
@@ -141,6 +170,7 @@ func beforeCommands(ctx *cli.Context) (err error) {
 	if _, err := os.Stat(cc.Keystore); os.IsNotExist(err) {
 		return fmt.Errorf("keystore dir %s is not exist", cc.Keystore)
 	}
+	keystore = cc.Keystore
 	if adm, err = wallet.LoadEthAccount(storage, cc.Keystore, cc.Admin, defaultAccPwd); err != nil {
 		return fmt.Errorf("load eth account for chain %d faild, err: %v", cc.SideChainID, err)
 	}
@@ -178,7 +208,15 @@ func handleSample(ctx *cli.Context) error {
 func handleCmdDeployECCDContract(ctx *cli.Context) error {
 	log.Info("start to deploy eccd contract...")
 
-	addr, err := sdk.DeployECCDContract(adm)
+	var (
+		addr common.Address
+		err  error
+	)
+	if ctx.Bool(getFlagName(Create2Flag)) {
+		addr, err = deployCreate2("eccd", eccd_abi.EthCrossChainDataABI, eccd_abi.EthCrossChainDataBin)
+	} else {
+		addr, err = sdk.DeployECCDContract(adm)
+	}
 	if err != nil {
 		return fmt.Errorf("deploy eccd for chain %d failed, err: %v", cc.SideChainID, err)
 	}
@@ -192,7 +230,16 @@ func handleCmdDeployECCMContract(ctx *cli.Context) error {
 	log.Info("start to deploy eccm contract...")
 
 	eccd := common.HexToAddress(cc.ECCD)
-	addr, err := sdk.DeployECCMContract(adm, eccd, cc.SideChainID)
+	var (
+		addr common.Address
+		err  error
+	)
+	if ctx.Bool(getFlagName(Create2Flag)) {
+		addr, err = deployCreate2("eccm", eccm_abi.EthCrossChainManagerABI, eccm_abi.EthCrossChainManagerBin,
+			eccd, cc.SideChainID)
+	} else {
+		addr, err = sdk.DeployECCMContract(adm, eccd, cc.SideChainID)
+	}
 	if err != nil {
 		return fmt.Errorf("deploy eccm for chain %d failed, err: %v", cc.SideChainID, err)
 	}
@@ -205,7 +252,16 @@ func handleCmdDeployCCMPContract(ctx *cli.Context) error {
 	log.Info("start to deploy ccmp contract...")
 
 	eccm := common.HexToAddress(cc.ECCM)
-	addr, err := sdk.DeployECCMPContract(adm, eccm)
+	var (
+		addr common.Address
+		err  error
+	)
+	if ctx.Bool(getFlagName(Create2Flag)) {
+		addr, err = deployCreate2("ccmp", eccmp_abi.EthCrossChainManagerProxyABI, eccmp_abi.EthCrossChainManagerProxyBin,
+			eccm)
+	} else {
+		addr, err = sdk.DeployECCMPContract(adm, eccm)
+	}
 	if err != nil {
 		return fmt.Errorf("deploy ccmp for chain %d failed, err: %v", cc.SideChainID, err)
 	}
@@ -214,13 +270,21 @@ func handleCmdDeployCCMPContract(ctx *cli.Context) error {
 	return updateConfig()
 }
 
+func handleCmdEstimateDeployCost(ctx *cli.Context) error {
+	log.Info("start to estimate deploy cost of chain %d...", cc.SideChainID)
+	return estimateDeployCost(context.Background())
+}
+
 func handleCmdBindERC20Asset(ctx *cli.Context) error {
 	log.Info("start to bind nft asset...")
 
 	srcAsset := flag2address(ctx, AssetFlag)
 	dstAsset := flag2address(ctx, DstAssetFlag)
 	dstChainId := flag2Uint64(ctx, DstChainFlag)
-	dstChainCfg := customSelectChainConfig(dstChainId)
+	dstChainCfg, err := dstChainConfig(dstChainId)
+	if err != nil {
+		return err
+	}
 	owner := xecdsa.Key2address(adm)
 	proxy := common.HexToAddress(cc.LockProxy)
 
@@ -249,6 +313,136 @@ func handleCmdBindERC20Asset(ctx *cli.Context) error {
 	return nil
 }
 
+func handleCmdBindTokens(ctx *cli.Context) error {
+	path := flag2string(ctx, TokenListFlag)
+	log.Info("start to bind tokens in %s...", path)
+
+	list, err := loadTokenList(path)
+	if err != nil {
+		return fmt.Errorf("load token list %s failed, err: %v", path, err)
+	}
+	c, cancel := context.WithTimeout(context.Background(), ctx.Duration(getFlagName(TimeoutFlag)))
+	defer cancel()
+	report, err := bindTokens(c, list)
+	if err != nil {
+		return fmt.Errorf("bind tokens of chain %d failed, err: %v", cc.SideChainID, err)
+	}
+	report.print()
+	if out := flag2string(ctx, ReportFlag); out != "" {
+		if err := files.WriteJsonFile(out, report, true); err != nil {
+			return err
+		}
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of %d tokens failed to bind", report.Failed, len(report.Items))
+	}
+	return nil
+}
+
+func handleCmdBindProxy(ctx *cli.Context) error {
+	log.Info("start to bind lock proxy...")
+
+	dstChainId := flag2Uint64(ctx, DstChainFlag)
+	dstChainCfg, err := dstChainConfig(dstChainId)
+	if err != nil {
+		return err
+	}
+	proxy := common.HexToAddress(cc.LockProxy)
+	dstProxy := common.HexToAddress(dstChainCfg.LockProxy)
+
+	bound, err := sdk.GetProxyHashMap(proxy, dstChainId)
+	if err != nil {
+		return fmt.Errorf("get proxy hash of chain %d failed, err: %v", dstChainId, err)
+	}
+	if common.BytesToAddress(bound) == dstProxy {
+		log.Info("lock proxy %s of chain %d already bound to proxy %s of chain %d",
+			cc.LockProxy, cc.SideChainID, dstChainCfg.LockProxy, dstChainId)
+		return nil
+	}
+
+	hash, err := sdk.BindProxyHash(adm, proxy, dstProxy, dstChainId)
+	if err != nil {
+		return fmt.Errorf("bind lock proxy (src chain id %d, src proxy %s) - (dst chain id %d, dst proxy %s) failed, err: %v",
+			cc.SideChainID, cc.LockProxy, dstChainId, dstChainCfg.LockProxy, err)
+	}
+	log.Info("bind lock proxy (src chain id %d, src proxy %s) - (dst chain id %d, dst proxy %s) success! txhash %s",
+		cc.SideChainID, cc.LockProxy, dstChainId, dstChainCfg.LockProxy, hash.Hex())
+	return nil
+}
+
+func handleCmdShowBindings(ctx *cli.Context) error {
+	chains := allChainConfigs()
+	if err := showProxyBindings(context.Background(), chains); err != nil {
+		return fmt.Errorf("show proxy bindings failed, err: %v", err)
+	}
+	if assets := flag2addresses(ctx, AssetFlag); len(assets) > 0 {
+		if err := showAssetBindings(context.Background(), chains, assets); err != nil {
+			return fmt.Errorf("show asset bindings failed, err: %v", err)
+		}
+	}
+	return nil
+}
+
+// outputReport prints the report in json or table, and writes it to the file of ReportFlag if set.
+// error is returned if any issue found.
+func outputReport(ctx *cli.Context, report interface{}, summary string, table func() string, issues int, what string) error {
+	if ctx.Bool(getFlagName(JsonFlag)) {
+		enc, err := json.MarshalIndent(report, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(enc))
+	} else {
+		log.Info("%s\r\n%s", summary, table())
+	}
+	if out := flag2string(ctx, ReportFlag); out != "" {
+		if err := files.WriteJsonFile(out, report, true); err != nil {
+			return err
+		}
+	}
+	if issues > 0 {
+		return fmt.Errorf("%d %s", issues, what)
+	}
+	return nil
+}
+
+func handleCmdCheckBindings(ctx *cli.Context) error {
+	path := flag2string(ctx, TokenListFlag)
+	list, err := loadTokenList(path)
+	if err != nil {
+		return fmt.Errorf("load token list %s failed, err: %v", path, err)
+	}
+	report, err := checkBindings(context.Background(), list)
+	if err != nil {
+		return err
+	}
+	return outputReport(ctx, report, fmt.Sprintf("bindings of %d pairs", len(report.Items)),
+		report.table, report.Issues, "binding issues found")
+}
+
+func handleCmdLiquidity(ctx *cli.Context) error {
+	path := flag2string(ctx, TokenListFlag)
+	list, err := loadTokenList(path)
+	if err != nil {
+		return fmt.Errorf("load token list %s failed, err: %v", path, err)
+	}
+	report, err := liquidity(context.Background(), list)
+	if err != nil {
+		return err
+	}
+	return outputReport(ctx, report, fmt.Sprintf("liquidity of %d assets", len(report.Assets)),
+		report.table, report.Issues, "liquidity issues found")
+}
+
+func handleCmdVerifyCode(ctx *cli.Context) error {
+	report, err := verifyCode(context.Background())
+	if err != nil {
+		return err
+	}
+	return outputReport(ctx, report, fmt.Sprintf("code of %d contracts", len(report.Items)),
+		report.table, report.Mismatches, "contracts mismatch the bindings")
+}
+
 func handleCmdTransferECCDOwnership(ctx *cli.Context) error {
 	log.Info("start to transfer eccd ownership...")
 
@@ -295,31 +489,33 @@ func handleCmdRegisterSideChain(ctx *cli.Context) error {
 	// todo: 验证heco的注册方式
 	eccd := common.HexToAddress(cc.ECCD)
 	chainID := cc.SideChainID
-	switch chainID {
-	case basedef.ETHEREUM_CROSSCHAIN_ID:
-		router := polyutils.ETH_ROUTER
-		err = polySdk.RegisterSideChain(validators[0], chainID, 1, router, eccd, cc.SideChainName)
-
-	case basedef.BSC_CROSSCHAIN_ID:
-		router := polyutils.BSC_ROUTER
-		ext := bsc.ExtraInfo{
-			ChainID: new(big.Int).SetUint64(chainID),
-		}
-		extEnc, _ := json.Marshal(ext)
-		err = polySdk.RegisterSideChainExt(validators[0], chainID, 1, router, eccd, cc.SideChainName, extEnc)
-
-	case basedef.HECO_CROSSCHAIN_ID:
-		router := polyutils.HECO_ROUTER
-		err = polySdk.RegisterSideChain(validators[0], chainID, 1, router, eccd, cc.SideChainName)
-
-	case basedef.OK_CROSSCHAIN_ID:
-		router := uint64(12)
-		err = polySdk.RegisterSideChain(validators[0], chainID, 1, router, eccd, cc.SideChainName)
+	status, err := polySdk.GetSideChainStatus(chainID)
+	if err != nil {
+		return fmt.Errorf("failed to get side chain %d status, err: %v", chainID, err)
+	}
+	if status.Registered != nil {
+		log.Info("chain %d already registered", chainID)
+		return nil
+	}
+	if status.RegisterRequest != nil {
+		log.Info("chain %d already requested, waiting for approve", chainID)
+		return nil
+	}
 
-	default:
-		err = fmt.Errorf("chain id %d invalid", chainID)
+	params, err := getSideChainParams(cc)
+	if err != nil {
+		return err
+	}
+	if err := checkBlocksToWait(chainID, params, ctx.Bool(getFlagName(ForceFlag))); err != nil {
+		return err
 	}
+	log.Info("register side chain %d name %s eccd %s, %s", chainID, cc.SideChainName, eccd.Hex(), params)
 
+	if params.ExtraInfo == nil {
+		err = polySdk.RegisterSideChain(validators[0], chainID, params.BlocksToWait, params.Router, eccd, cc.SideChainName)
+	} else {
+		err = polySdk.RegisterSideChainExt(validators[0], chainID, params.BlocksToWait, params.Router, eccd, cc.SideChainName, params.ExtraInfo)
+	}
 	if err != nil {
 		return err
 	}
@@ -337,6 +533,17 @@ func handleCmdApproveSideChain(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
+	status, err := polySdk.GetSideChainStatus(cc.SideChainID)
+	if err != nil {
+		return fmt.Errorf("failed to get side chain %d status, err: %v", cc.SideChainID, err)
+	}
+	if status.Registered != nil && status.RegisterRequest == nil {
+		log.Info("chain %d already approved", cc.SideChainID)
+		return nil
+	}
+	if status.RegisterRequest == nil {
+		return fmt.Errorf("chain %d has no register request", cc.SideChainID)
+	}
 	if err := polySdk.ApproveRegisterSideChain(cc.SideChainID, validators); err != nil {
 		return fmt.Errorf("failed to approve register side chain, err: %s", err)
 	}
@@ -345,6 +552,280 @@ func handleCmdApproveSideChain(ctx *cli.Context) error {
 	return nil
 }
 
+func handleCmdUpdateSideChain(ctx *cli.Context) error {
+	validators, err := wallet.LoadPolyAccountList(cfg.Poly.Keystore, cfg.Poly.Passphrase)
+	if err != nil {
+		return err
+	}
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+
+	eccd := common.HexToAddress(cc.ECCD)
+	chainID := cc.SideChainID
+	status, err := polySdk.GetSideChainStatus(chainID)
+	if err != nil {
+		return fmt.Errorf("failed to get side chain %d status, err: %v", chainID, err)
+	}
+	if status.Registered == nil {
+		return fmt.Errorf("chain %d is not registered", chainID)
+	}
+	if status.UpdateRequest != nil {
+		log.Info("chain %d update already requested, waiting for approve", chainID)
+		return nil
+	}
+
+	params, err := getSideChainParams(cc)
+	if err != nil {
+		return err
+	}
+	if err := checkBlocksToWait(chainID, params, ctx.Bool(getFlagName(ForceFlag))); err != nil {
+		return err
+	}
+	log.Info("update side chain %d name %s eccd %s, %s", chainID, cc.SideChainName, eccd.Hex(), params)
+
+	if params.ExtraInfo == nil {
+		err = polySdk.UpdateSideChain(validators[0], chainID, params.BlocksToWait, params.Router, eccd, cc.SideChainName)
+	} else {
+		err = polySdk.UpdateSideChainExt(validators[0], chainID, params.BlocksToWait, params.Router, eccd, cc.SideChainName, params.ExtraInfo)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update side chain %d, err: %v", chainID, err)
+	}
+
+	log.Info("update side chain %d eccd %s success", chainID, eccd.Hex())
+	return nil
+}
+
+func handleCmdApproveUpdateSideChain(ctx *cli.Context) error {
+	validators, err := wallet.LoadPolyAccountList(cfg.Poly.Keystore, cfg.Poly.Passphrase)
+	if err != nil {
+		return err
+	}
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+	status, err := polySdk.GetSideChainStatus(cc.SideChainID)
+	if err != nil {
+		return fmt.Errorf("failed to get side chain %d status, err: %v", cc.SideChainID, err)
+	}
+	if status.UpdateRequest == nil {
+		return fmt.Errorf("chain %d has no update request", cc.SideChainID)
+	}
+	if err := polySdk.ApproveUpdateSideChain(cc.SideChainID, validators); err != nil {
+		return fmt.Errorf("failed to approve update side chain, err: %s", err)
+	}
+
+	log.Info("approve update side chain %d success", cc.SideChainID)
+	return nil
+}
+
+func handleCmdQuitSideChain(ctx *cli.Context) error {
+	validators, err := wallet.LoadPolyAccountList(cfg.Poly.Keystore, cfg.Poly.Passphrase)
+	if err != nil {
+		return err
+	}
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+	status, err := polySdk.GetSideChainStatus(cc.SideChainID)
+	if err != nil {
+		return fmt.Errorf("failed to get side chain %d status, err: %v", cc.SideChainID, err)
+	}
+	if status.Registered == nil {
+		return fmt.Errorf("chain %d is not registered", cc.SideChainID)
+	}
+	if status.QuitRequested {
+		log.Info("chain %d quit already requested, waiting for approve", cc.SideChainID)
+		return nil
+	}
+	if err := polySdk.QuitSideChain(validators[0], cc.SideChainID); err != nil {
+		return fmt.Errorf("failed to quit side chain %d, err: %v", cc.SideChainID, err)
+	}
+
+	log.Info("quit side chain %d success", cc.SideChainID)
+	return nil
+}
+
+func handleCmdApproveQuitSideChain(ctx *cli.Context) error {
+	validators, err := wallet.LoadPolyAccountList(cfg.Poly.Keystore, cfg.Poly.Passphrase)
+	if err != nil {
+		return err
+	}
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+	status, err := polySdk.GetSideChainStatus(cc.SideChainID)
+	if err != nil {
+		return fmt.Errorf("failed to get side chain %d status, err: %v", cc.SideChainID, err)
+	}
+	if !status.QuitRequested {
+		return fmt.Errorf("chain %d has no quit request", cc.SideChainID)
+	}
+	if err := polySdk.ApproveQuitSideChain(cc.SideChainID, validators); err != nil {
+		return fmt.Errorf("failed to approve quit side chain, err: %s", err)
+	}
+
+	log.Info("approve quit side chain %d success", cc.SideChainID)
+	return nil
+}
+
+func handleCmdSideChainInfo(ctx *cli.Context) error {
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+
+	chainID := cc.SideChainID
+	status, err := polySdk.GetSideChainStatus(chainID)
+	if err != nil {
+		return fmt.Errorf("failed to get side chain %d status, err: %v", chainID, err)
+	}
+	hsStatus, err := polySdk.GetHeaderSyncStatus(chainID)
+	if err != nil {
+		return fmt.Errorf("failed to get side chain %d header sync status, err: %v", chainID, err)
+	}
+	log.Info("side chain %d status: register requested %v, update requested %v, quit requested %v, "+
+		"genesis synced %v, header height %d",
+		chainID, status.RegisterRequest != nil, status.UpdateRequest != nil, status.QuitRequested,
+		hsStatus.GenesisSynced, hsStatus.Height)
+
+	sideChain := status.Registered
+	if sideChain == nil {
+		log.Info("side chain %d is not registered on poly", chainID)
+		return nil
+	}
+
+	eccd := common.BytesToAddress(sideChain.CCMCAddress)
+	log.Info("side chain %d on poly: name %s, router %d, blocksToWait %d, eccd %s, owner %s, extra %s",
+		sideChain.ChainId, sideChain.Name, sideChain.Router, sideChain.BlocksToWait,
+		eccd.Hex(), sideChain.Address.ToHexString(), string(sideChain.ExtraInfo))
+
+	params, err := getSideChainParams(cc)
+	if err != nil {
+		return err
+	}
+	log.Info("side chain %d in config: %s", chainID, params)
+	diffs := diffSideChain(sideChain, cc, params)
+	if len(diffs) == 0 {
+		log.Info("side chain %d registration matches config", chainID)
+		return nil
+	}
+	for _, diff := range diffs {
+		log.Warn("side chain %d %s", chainID, diff)
+	}
+	return nil
+}
+
+func handleCmdRegisterCandidate(ctx *cli.Context) error {
+	node, err := loadNodeAccount(ctx)
+	if err != nil {
+		return err
+	}
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+
+	peer := vconfig.PubkeyID(node.PublicKey)
+	if err := polySdk.RegisterCandidate(peer, node); err != nil {
+		return fmt.Errorf("failed to register candidate %s, err: %v", peer, err)
+	}
+	log.Info("register candidate %s success, node address %s", peer, node.Address.ToBase58())
+	return nil
+}
+
+func handleCmdApproveCandidate(ctx *cli.Context) error {
+	peer, err := flag2peer(ctx)
+	if err != nil {
+		return err
+	}
+	validators, err := wallet.LoadPolyAccountList(cfg.Poly.Keystore, cfg.Poly.Passphrase)
+	if err != nil {
+		return err
+	}
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+
+	if err := polySdk.ApproveCandidate(peer, validators); err != nil {
+		return fmt.Errorf("failed to approve candidate %s, err: %v", peer, err)
+	}
+	log.Info("approve candidate %s success", peer)
+	return nil
+}
+
+func handleCmdUnRegisterCandidate(ctx *cli.Context) error {
+	node, err := loadNodeAccount(ctx)
+	if err != nil {
+		return err
+	}
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+
+	peer := vconfig.PubkeyID(node.PublicKey)
+	if err := polySdk.UnRegisterCandidate(peer, node); err != nil {
+		return fmt.Errorf("failed to unregister candidate %s, err: %v", peer, err)
+	}
+	log.Info("unregister candidate %s success", peer)
+	return nil
+}
+
+func handleCmdQuitNode(ctx *cli.Context) error {
+	node, err := loadNodeAccount(ctx)
+	if err != nil {
+		return err
+	}
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+
+	peer := vconfig.PubkeyID(node.PublicKey)
+	if err := polySdk.QuitNode(peer, node); err != nil {
+		return fmt.Errorf("failed to quit node %s, err: %v", peer, err)
+	}
+	log.Info("quit node %s success, commit dpos to make it effective", peer)
+	return nil
+}
+
+func handleCmdListPeers(ctx *cli.Context) error {
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+	return dumpPolyPeers(polySdk)
+}
+
+func handleCmdCommitDpos(ctx *cli.Context) error {
+	validators, err := wallet.LoadPolyAccountList(cfg.Poly.Keystore, cfg.Poly.Passphrase)
+	if err != nil {
+		return err
+	}
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+
+	log.Info("peers before commit dpos:")
+	if err := dumpPolyPeers(polySdk); err != nil {
+		return err
+	}
+	if err := polySdk.CommitPolyDpos(validators); err != nil {
+		return fmt.Errorf("failed to commit dpos, err: %v", err)
+	}
+
+	log.Info("commit dpos success, peers after commit dpos:")
+	return dumpPolyPeers(polySdk)
+}
+
 func handleCmdSyncSideChainGenesis2Poly(ctx *cli.Context) error {
 	log.Info("start to sync side chain %s genesis header to poly chain...", cc.SideChainName)
 
@@ -357,18 +838,27 @@ func handleCmdSyncSideChainGenesis2Poly(ctx *cli.Context) error {
 		return err
 	}
 
+	hsStatus, err := polySdk.GetHeaderSyncStatus(cc.SideChainID)
+	if err != nil {
+		return fmt.Errorf("failed to get side chain %d header sync status, err: %v", cc.SideChainID, err)
+	}
+	if hsStatus.GenesisSynced {
+		log.Info("side chain %d already synced, current header height on poly %d", cc.SideChainID, hsStatus.Height)
+		return nil
+	}
+
 	switch cc.SideChainID {
 	case basedef.ETHEREUM_CROSSCHAIN_ID:
-		err = SyncEthGenesisHeader2Poly(cc.SideChainID, sdk, polySdk, validators)
+		err = SyncEthGenesisHeader2Poly(cc.SideChainID, sdk, polySdk, validators, cc.HeaderProfile)
 	case basedef.BSC_CROSSCHAIN_ID:
-		err = SyncBscGenesisHeader2Poly(cc.SideChainID, sdk, polySdk, validators)
+		err = SyncBscGenesisHeader2Poly(cc.SideChainID, sdk, polySdk, validators, cc.HeaderProfile)
 	case basedef.HECO_CROSSCHAIN_ID:
-		err = SyncHecoGenesisHeader2Poly(cc.SideChainID, sdk, polySdk, validators)
+		err = SyncHecoGenesisHeader2Poly(cc.SideChainID, sdk, polySdk, validators, cc.HeaderProfile)
 	case basedef.OK_CROSSCHAIN_ID:
 		hexpath := flag2string(ctx, HexFlag)
-		dec, err := files.ReadHexFile(hexpath)
-		if err != nil {
-			return err
+		dec, rerr := files.ReadHexFile(hexpath)
+		if rerr != nil {
+			return rerr
 		}
 		err = SyncOKGenesisHeader2Poly(cc.SideChainID, polySdk, validators, dec)
 	default:
@@ -404,12 +894,110 @@ func handleCmdSyncPolyGenesis2SideChain(ctx *cli.Context) error {
 }
 
 func handleGetNativeBalance(ctx *cli.Context) error {
-	owner := flag2address(ctx, SrcAccountFlag)
-	balance, err := sdk.GetNativeBalance(owner)
+	owners := flag2addresses(ctx, SrcAccountFlag)
+	balances, err := sdk.BatchBalanceAt(context.Background(), owners)
 	if err != nil {
 		return fmt.Errorf("get native balance faild, err: %v", err)
 	}
-	log.Info("%s native balance is %s", owner.Hex(), balance.String())
+	for i, owner := range owners {
+		log.Info("%s native balance is %s", owner.Hex(), formatNative(balances[i]))
+	}
+	return nil
+}
+
+func handleCmdContractOwners(ctx *cli.Context) error {
+	names := []string{"eccd", "eccm", "ccmp", "lock proxy"}
+	contracts := []common.Address{
+		common.HexToAddress(cc.ECCD),
+		common.HexToAddress(cc.ECCM),
+		common.HexToAddress(cc.CCMP),
+		common.HexToAddress(cc.LockProxy),
+	}
+	owners, err := sdk.GetOwnerships(context.Background(), common.HexToAddress(cc.Multicall), contracts)
+	if err != nil {
+		return fmt.Errorf("get owners failed, err: %v", err)
+	}
+	for i, name := range names {
+		log.Info("%s %s owner is %s", name, contracts[i].Hex(), owners[i].Hex())
+	}
+	return nil
+}
+
+func handleCmdTrack(ctx *cli.Context) error {
+	hash := common.HexToHash(flag2string(ctx, TxHashFlag))
+	log.Info("start to track cross chain tx %s of chain %s...", hash.Hex(), cc.SideChainName)
+
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+	tracker := &crossChainTracker{
+		src:       sdk,
+		srcCfg:    cc,
+		poly:      polySdk,
+		polyStart: flag2Uint64(ctx, PolyHeightFlag),
+		dstStart:  flag2Uint64(ctx, DstHeightFlag),
+	}
+	defer tracker.close()
+
+	c, cancel := context.WithTimeout(context.Background(), ctx.Duration(getFlagName(TimeoutFlag)))
+	defer cancel()
+	if err := tracker.track(c, hash); err != nil {
+		return fmt.Errorf("track cross chain tx %s failed, err: %v", hash.Hex(), err)
+	}
+	log.Info("cross chain tx %s finished in %s", hash.Hex(), time.Since(tracker.started).Truncate(time.Second))
+	return nil
+}
+
+func handleCmdSmokeTest(ctx *cli.Context) error {
+	from := flag2address(ctx, SrcAccountFlag)
+	key, err := wallet.LoadEthAccount(storage, keystore, from.Hex(), defaultAccPwd)
+	if err != nil {
+		return err
+	}
+	to := from
+	if flag2string(ctx, DstAccountFlag) != "" {
+		to = flag2address(ctx, DstAccountFlag)
+	}
+	asset, err := getAssetMeta(ctx)
+	if err != nil {
+		return err
+	}
+	amount, err := flag2amount(ctx, AmountFlag, asset, 0)
+	if err != nil {
+		return err
+	}
+	dstChainId := flag2Uint64(ctx, DstChainFlag)
+	dstCfg, err := dstChainConfig(dstChainId)
+	if err != nil {
+		return err
+	}
+	log.Info("start to smoke test from chain %d to chain %d...", cc.SideChainID, dstChainId)
+
+	polySdk, err := chainsdk.NewPolySdkAndSetChainID(cfg.Poly.RPC)
+	if err != nil {
+		return err
+	}
+	test := &smokeTest{
+		key:    key,
+		from:   from,
+		to:     to,
+		asset:  asset.Token,
+		amount: amount,
+		dstCfg: dstCfg,
+		tracker: &crossChainTracker{
+			src:    sdk,
+			srcCfg: cc,
+			poly:   polySdk,
+		},
+	}
+	defer test.close()
+
+	c, cancel := context.WithTimeout(context.Background(), ctx.Duration(getFlagName(TimeoutFlag)))
+	defer cancel()
+	if err := test.run(c); err != nil {
+		return fmt.Errorf("smoke test from chain %d to chain %d failed, err: %v", cc.SideChainID, dstChainId, err)
+	}
 	return nil
 }
 
@@ -423,12 +1011,15 @@ func handleCmdNativeTransfer(ctx *cli.Context) error {
 	}
 
 	to := flag2address(ctx, DstAccountFlag)
-	amount := flag2big(ctx, AmountFlag)
+	amount, err := flag2amount(ctx, AmountFlag, nil, 0)
+	if err != nil {
+		return err
+	}
 	tx, err := sdk.TransferNative(key, to, amount)
 	if err != nil {
 		return err
 	}
-	log.Info("%s transfer %s to %s success, txhash %s", from.Hex(), amount.String(), to.Hex(), tx.Hex())
+	log.Info("%s transfer %s to %s success, txhash %s", from.Hex(), formatNative(amount), to.Hex(), tx.Hex())
 	return nil
 }
 
@@ -491,10 +1082,16 @@ func flag2address(ctx *cli.Context, f cli.Flag) common.Address {
 	return common.HexToAddress(data)
 }
 
-func flag2big(ctx *cli.Context, f cli.Flag) *big.Int {
-	fn := getFlagName(f)
-	data := ctx.String(fn)
-	return math.String2BigInt(data)
+// flag2addresses parses addresses separated by comma.
+func flag2addresses(ctx *cli.Context, f cli.Flag) []common.Address {
+	data := strings.Split(flag2string(ctx, f), ",")
+	list := make([]common.Address, 0, len(data))
+	for _, addr := range data {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			list = append(list, common.HexToAddress(addr))
+		}
+	}
+	return list
 }
 
 func flag2Uint64(ctx *cli.Context, f cli.Flag) uint64 {
@@ -503,6 +1100,44 @@ func flag2Uint64(ctx *cli.Context, f cli.Flag) uint64 {
 	return data
 }
 
+// findChainConfig returns config of chain `chainID`, error if the chain is not supported by this tool
+// or not configured.
+func findChainConfig(chainID uint64) (*ChainConfig, error) {
+	var c *ChainConfig
+	switch chainID {
+	case basedef.ETHEREUM_CROSSCHAIN_ID:
+		c = cfg.Ethereum
+	case basedef.BSC_CROSSCHAIN_ID:
+		c = cfg.Bsc
+	case basedef.HECO_CROSSCHAIN_ID:
+		c = cfg.Heco
+	case basedef.OK_CROSSCHAIN_ID:
+		c = cfg.Ok
+	default:
+		return nil, fmt.Errorf("chain %d is not supported", chainID)
+	}
+	if c == nil {
+		return nil, fmt.Errorf("chain %d is not configured", chainID)
+	}
+	return c, nil
+}
+
+// dstChainConfig returns config of dest chain which has lock proxy deployed, the current chain is
+// not allowed.
+func dstChainConfig(chainID uint64) (*ChainConfig, error) {
+	if chainID == cc.SideChainID {
+		return nil, fmt.Errorf("dst chain %d is the current chain", chainID)
+	}
+	c, err := findChainConfig(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("dst %v", err)
+	}
+	if !common.IsHexAddress(c.LockProxy) || common.HexToAddress(c.LockProxy) == chainsdk.EmptyAddress {
+		return nil, fmt.Errorf("lock proxy of dst chain %d is invalid: %q", chainID, c.LockProxy)
+	}
+	return c, nil
+}
+
 func customSelectChainConfig(chainID uint64) *ChainConfig {
 	switch chainID {
 	case basedef.ETHEREUM_CROSSCHAIN_ID:
@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"time"
+
+	"poly-bridge/chainsdk"
+
+	log "github.com/astaxie/beego/logs"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// smokeTest locks a small amount of asset on source chain and verifies it's unlocked on dest chain.
+type smokeTest struct {
+	key      *ecdsa.PrivateKey
+	from     common.Address
+	to       common.Address
+	asset    common.Address
+	amount   *big.Int
+	dstCfg   *ChainConfig
+	dst      *chainsdk.EthereumSdk
+	dstAsset common.Address
+	tracker  *crossChainTracker
+}
+
+func (st *smokeTest) close() {
+	if st.dst != nil {
+		st.dst.Close()
+	}
+	st.tracker.close()
+}
+
+func (st *smokeTest) run(ctx context.Context) (err error) {
+	started := time.Now()
+	srcProxy := common.HexToAddress(cc.LockProxy)
+
+	bound, err := sdk.GetAssetHashMap(srcProxy, st.asset, st.dstCfg.SideChainID)
+	if err != nil {
+		return err
+	}
+	if len(bound) == 0 {
+		return fmt.Errorf("asset %s is not bound to chain %d in lock proxy %s", st.asset.Hex(), st.dstCfg.SideChainID, cc.LockProxy)
+	}
+	st.dstAsset = common.BytesToAddress(bound)
+	if st.dst, err = chainsdk.NewEthereumSdk(st.dstCfg.RPC); err != nil {
+		return err
+	}
+	before, err := st.dst.GetAssetBalance(st.dstAsset, st.to)
+	if err != nil {
+		return err
+	}
+
+	// search poly and dest chain from current heights, the tx is relayed after lock
+	if st.tracker.polyStart, err = st.tracker.poly.GetCurrentBlockHeight(ctx); err != nil {
+		return err
+	}
+	if st.tracker.dstStart, err = st.dst.GetCurrentBlockHeight(ctx); err != nil {
+		return err
+	}
+
+	if st.asset != chainsdk.NativeFeeToken {
+		allowance, err := sdk.GetERC20Allowance(ctx, st.asset, st.from, srcProxy)
+		if err != nil {
+			return err
+		}
+		if allowance.Cmp(st.amount) < 0 {
+			hop := time.Now()
+			hash, err := sdk.ApproveERC20(st.key, st.asset, srcProxy, st.amount)
+			if err != nil {
+				return fmt.Errorf("approve %s to lock proxy failed, err: %v", st.asset.Hex(), err)
+			}
+			if err := waitTxSucceeded(ctx, hash, "approve"); err != nil {
+				return err
+			}
+			log.Info("[approve] tx %s, hop %s", hash.Hex(), time.Since(hop).Truncate(time.Second))
+		}
+	}
+
+	hop := time.Now()
+	hash, err := sdk.LockAsset(st.key, srcProxy, st.asset, st.dstCfg.SideChainID, st.to.Bytes(), st.amount)
+	if err != nil {
+		return fmt.Errorf("lock %s failed, err: %v", st.asset.Hex(), err)
+	}
+	if err := waitTxSucceeded(ctx, hash, "lock"); err != nil {
+		return err
+	}
+	log.Info("[lock] tx %s, hop %s", hash.Hex(), time.Since(hop).Truncate(time.Second))
+
+	if err := st.tracker.track(ctx, hash); err != nil {
+		return err
+	}
+	if err := st.verifyUnlock(before); err != nil {
+		return err
+	}
+	log.Info("smoke test from chain %d to chain %d passed in %s", cc.SideChainID, st.dstCfg.SideChainID,
+		time.Since(started).Truncate(time.Second))
+	return nil
+}
+
+// waitTxSucceeded waits until the tx is packed and fails if it's reverted, sdk returns without error
+// in both cases when it sends tx.
+func waitTxSucceeded(ctx context.Context, hash common.Hash, what string) error {
+	for {
+		receipt, err := sdk.GetTransactionReceipt(ctx, hash)
+		switch {
+		case err == nil && receipt.Status == types.ReceiptStatusSuccessful:
+			return nil
+		case err == nil:
+			return fmt.Errorf("%s reverted, tx %s at height %d", what, hash.Hex(), receipt.BlockNumber)
+		case err != ethereum.NotFound:
+			return fmt.Errorf("get receipt of %s tx %s failed, err: %v", what, hash.Hex(), err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s tx %s is not packed, %v", what, hash.Hex(), ctx.Err())
+		case <-time.After(trackInterval):
+		}
+	}
+}
+
+// verifyUnlock checks the UnlockEvent of dest lock proxy and the balance change of recipient.
+func (st *smokeTest) verifyUnlock(before *big.Int) error {
+	dstProxy := common.HexToAddress(st.dstCfg.LockProxy)
+	events, err := chainsdk.GetUnlockEvents(dstProxy, st.tracker.dstTx)
+	if err != nil {
+		return err
+	}
+	var unlocked *big.Int
+	for _, evt := range events {
+		if evt.ToAddress == st.to && evt.ToAssetHash == st.dstAsset {
+			unlocked = evt.Amount
+		}
+	}
+	if unlocked == nil {
+		return fmt.Errorf("no UnlockEvent of asset %s to %s in dest tx %s",
+			st.dstAsset.Hex(), st.to.Hex(), st.tracker.dstTx.TxHash.Hex())
+	}
+	log.Info("[unlock] asset %s, to %s, amount %s", st.dstAsset.Hex(), st.to.Hex(), unlocked.String())
+
+	after, err := st.dst.GetAssetBalance(st.dstAsset, st.to)
+	if err != nil {
+		return err
+	}
+	if diff := new(big.Int).Sub(after, before); diff.Cmp(unlocked) != 0 {
+		return fmt.Errorf("balance of %s changed %s, expected %s", st.to.Hex(), diff.String(), unlocked.String())
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"poly-bridge/chainsdk"
+	"poly-bridge/utils/wallet"
+
+	log "github.com/astaxie/beego/logs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli"
+)
+
+// getAssetMeta reads symbol and decimals of --asset on current chain, empty address is native coin.
+func getAssetMeta(ctx *cli.Context) (*chainsdk.ERC20Meta, error) {
+	if !common.IsHexAddress(flag2string(ctx, AssetFlag)) {
+		return nil, fmt.Errorf("invalid asset %q", flag2string(ctx, AssetFlag))
+	}
+	asset := flag2address(ctx, AssetFlag)
+	metas, err := sdk.GetERC20Metas(context.Background(), common.HexToAddress(cc.Multicall), []common.Address{asset})
+	if err != nil {
+		return nil, err
+	}
+	return metas[0], metas[0].Err
+}
+
+// getTokenMeta is getAssetMeta of erc20 token.
+func getTokenMeta(ctx *cli.Context) (*chainsdk.ERC20Meta, error) {
+	meta, err := getAssetMeta(ctx)
+	if err == nil && meta.Token == chainsdk.NativeFeeToken {
+		return nil, fmt.Errorf("asset is native coin, use nativeBalance or transferNative instead")
+	}
+	return meta, err
+}
+
+// flag2tokenAmount parses --amount in token unit, e.g: 1.5 or 1.5 USDT.
+func flag2tokenAmount(ctx *cli.Context, meta *chainsdk.ERC20Meta) (*big.Int, error) {
+	return flag2amount(ctx, AmountFlag, meta, meta.Decimals)
+}
+
+// flag2spender returns --to, or lock proxy of current chain if it's not set.
+func flag2spender(ctx *cli.Context) common.Address {
+	if flag2string(ctx, DstAccountFlag) == "" {
+		return common.HexToAddress(cc.LockProxy)
+	}
+	return flag2address(ctx, DstAccountFlag)
+}
+
+// flag2accounts parses accounts separated by comma in flag `f`, at least one is required.
+func flag2accounts(ctx *cli.Context, f cli.Flag) ([]common.Address, error) {
+	data := strings.Split(flag2string(ctx, f), ",")
+	list := make([]common.Address, 0, len(data))
+	for _, addr := range data {
+		if addr = strings.TrimSpace(addr); !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("invalid %s account %q", getFlagName(f), addr)
+		}
+		list = append(list, common.HexToAddress(addr))
+	}
+	return list, nil
+}
+
+func handleCmdTokenBalance(ctx *cli.Context) error {
+	owners, err := flag2accounts(ctx, SrcAccountFlag)
+	if err != nil {
+		return err
+	}
+	meta, err := getTokenMeta(ctx)
+	if err != nil {
+		return err
+	}
+	for _, owner := range owners {
+		balance, err := sdk.GetERC20Balance(context.Background(), meta.Token, owner)
+		if err != nil {
+			return fmt.Errorf("get %s balance of %s failed, err: %v", meta.Symbol, owner.Hex(), err)
+		}
+		log.Info("%s balance is %s", owner.Hex(), formatToken(balance, meta))
+	}
+	return nil
+}
+
+func handleCmdTokenAllowance(ctx *cli.Context) error {
+	if !common.IsHexAddress(flag2string(ctx, SrcAccountFlag)) {
+		return fmt.Errorf("invalid owner %q", flag2string(ctx, SrcAccountFlag))
+	}
+	meta, err := getTokenMeta(ctx)
+	if err != nil {
+		return err
+	}
+	owner, spender := flag2address(ctx, SrcAccountFlag), flag2spender(ctx)
+	allowance, err := sdk.GetERC20Allowance(context.Background(), meta.Token, owner, spender)
+	if err != nil {
+		return fmt.Errorf("get %s allowance of %s failed, err: %v", meta.Symbol, owner.Hex(), err)
+	}
+	log.Info("%s allowance to %s is %s", owner.Hex(), spender.Hex(), formatToken(allowance, meta))
+	return nil
+}
+
+func handleCmdTokenApprove(ctx *cli.Context) error {
+	meta, err := getTokenMeta(ctx)
+	if err != nil {
+		return err
+	}
+	amount, err := flag2tokenAmount(ctx, meta)
+	if err != nil {
+		return err
+	}
+	owner, spender := flag2address(ctx, SrcAccountFlag), flag2spender(ctx)
+	key, err := wallet.LoadEthAccount(storage, keystore, owner.Hex(), defaultAccPwd)
+	if err != nil {
+		return err
+	}
+	hash, err := sdk.ApproveERC20(key, meta.Token, spender, amount)
+	if err != nil {
+		return fmt.Errorf("%s approve %s to %s failed, err: %v", owner.Hex(), meta.Symbol, spender.Hex(), err)
+	}
+	log.Info("%s approve %s to %s success, txhash %s", owner.Hex(), formatToken(amount, meta), spender.Hex(),
+		hash.Hex())
+	return nil
+}
+
+func handleCmdTokenTransfer(ctx *cli.Context) error {
+	meta, err := getTokenMeta(ctx)
+	if err != nil {
+		return err
+	}
+	amount, err := flag2tokenAmount(ctx, meta)
+	if err != nil {
+		return err
+	}
+	from, to := flag2address(ctx, SrcAccountFlag), flag2address(ctx, DstAccountFlag)
+	if to == (common.Address{}) {
+		return fmt.Errorf("invalid to address %q", flag2string(ctx, DstAccountFlag))
+	}
+	key, err := wallet.LoadEthAccount(storage, keystore, from.Hex(), defaultAccPwd)
+	if err != nil {
+		return err
+	}
+	hash, err := sdk.TransferERC20(key, meta.Token, to, amount)
+	if err != nil {
+		return fmt.Errorf("%s transfer %s to %s failed, err: %v", from.Hex(), meta.Symbol, to.Hex(), err)
+	}
+	log.Info("%s transfer %s to %s success, txhash %s", from.Hex(), formatToken(amount, meta), to.Hex(),
+		hash.Hex())
+	return nil
+}
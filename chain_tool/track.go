@@ -0,0 +1,282 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"poly-bridge/basedef"
+	"poly-bridge/chainsdk"
+	"poly-bridge/go_abi/eccm_abi"
+
+	log "github.com/astaxie/beego/logs"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	trackInterval = 5 * time.Second
+
+	// blocks searched backward when start height is not set, the cross chain tx may be relayed
+	// before tracking starts.
+	defaultPolyLookback = uint64(1000)
+	defaultDstLookback  = uint64(5000)
+)
+
+func stateName(state int) string {
+	switch state {
+	case basedef.STATE_PENDDING:
+		return "pending"
+	case basedef.STATE_SOURCE_DONE:
+		return "source done"
+	case basedef.STATE_SOURCE_CONFIRMED:
+		return "source confirmed"
+	case basedef.STATE_POLY_CONFIRMED:
+		return "poly confirmed"
+	case basedef.STATE_DESTINATION_DONE:
+		return "destination done"
+	case basedef.STATE_FINISHED:
+		return "finished"
+	}
+	return fmt.Sprintf("unknown state %d", state)
+}
+
+// crossChainTracker follows a cross chain tx through source chain, poly and destination chain.
+type crossChainTracker struct {
+	src       *chainsdk.EthereumSdk
+	srcCfg    *ChainConfig
+	poly      *chainsdk.PolySDK
+	polyStart uint64
+	dstStart  uint64
+
+	state   int
+	started time.Time
+	last    time.Time
+
+	// set as the tx goes on
+	receipt *types.Receipt
+	event   *eccm_abi.EthCrossChainManagerCrossChainEvent
+	proof   *chainsdk.PolyMakeProof
+	dstCfg  *ChainConfig
+	dst     *chainsdk.EthereumSdk
+	execute *eccm_abi.EthCrossChainManagerVerifyHeaderAndExecuteTxEvent
+	dstTx   *types.Receipt
+}
+
+func (t *crossChainTracker) close() {
+	if t.dst != nil {
+		t.dst.Close()
+	}
+}
+
+func (t *crossChainTracker) report(state int, chainID, height uint64, timestamp uint64, hash string) {
+	now := time.Now()
+	log.Info("[%s] chain %d, height %d, block time %s, tx %s, hop %s, elapsed %s", stateName(state), chainID, height,
+		time.Unix(int64(timestamp), 0).Format(time.RFC3339), hash,
+		now.Sub(t.last).Truncate(time.Second), now.Sub(t.started).Truncate(time.Second))
+	t.state, t.last = state, now
+}
+
+// stallHint tells which party should move the cross chain tx on after `state`.
+func stallHint(state int) string {
+	switch state {
+	case basedef.STATE_PENDDING:
+		return "source tx is not packed"
+	case basedef.STATE_SOURCE_DONE:
+		return "source chain does not reach blocks to wait"
+	case basedef.STATE_SOURCE_CONFIRMED:
+		return "side chain relayer does not commit the tx to poly"
+	case basedef.STATE_POLY_CONFIRMED:
+		return "poly relayer does not commit the tx to dest chain"
+	}
+	return "unknown"
+}
+
+// poll calls fn every trackInterval until it's done or ctx is done.
+func (t *crossChainTracker) poll(ctx context.Context, fn func() (bool, error)) error {
+	for {
+		done, err := fn()
+		if err != nil || done {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("stalled after %s (%s), %v", stateName(t.state), stallHint(t.state), ctx.Err())
+		case <-time.After(trackInterval):
+		}
+	}
+}
+
+func (t *crossChainTracker) headerTime(ctx context.Context, sdk *chainsdk.EthereumSdk, height uint64) uint64 {
+	hdr, err := sdk.GetHeaderByNumber(ctx, height)
+	if err != nil {
+		log.Warn("get header %d failed, err: %v", height, err)
+		return 0
+	}
+	return hdr.Time
+}
+
+func (t *crossChainTracker) track(ctx context.Context, hash common.Hash) error {
+	t.started = time.Now()
+	t.last = t.started
+	t.state = basedef.STATE_PENDDING
+	log.Info("[%s] chain %d, tx %s", stateName(t.state), t.srcCfg.SideChainID, hash.Hex())
+
+	steps := []func(context.Context, common.Hash) error{
+		t.waitSourceDone,
+		t.waitSourceConfirmed,
+		t.waitPolyConfirmed,
+		t.waitDestinationDone,
+		t.waitFinished,
+	}
+	for _, step := range steps {
+		if err := step(ctx, hash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *crossChainTracker) waitSourceDone(ctx context.Context, hash common.Hash) error {
+	if err := t.poll(ctx, func() (bool, error) {
+		receipt, err := t.src.GetTransactionReceipt(ctx, hash)
+		if err == ethereum.NotFound {
+			return false, nil
+		}
+		t.receipt = receipt
+		return err == nil, err
+	}); err != nil {
+		return err
+	}
+	if t.receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("source tx %s failed at height %d", hash.Hex(), t.receipt.BlockNumber)
+	}
+	events, err := chainsdk.GetCrossChainEvents(common.HexToAddress(t.srcCfg.ECCM), t.receipt)
+	if err != nil {
+		return err
+	}
+	if len(events) == 0 {
+		return fmt.Errorf("no CrossChainEvent of eccm %s in source tx %s", t.srcCfg.ECCM, hash.Hex())
+	}
+	t.event = events[0]
+	height := t.receipt.BlockNumber.Uint64()
+	t.report(basedef.STATE_SOURCE_DONE, t.srcCfg.SideChainID, height, t.headerTime(ctx, t.src, height), hash.Hex())
+	log.Info("cross chain tx id %x, to chain %d, to contract %x", t.event.TxId, t.event.ToChainId, t.event.ToContract)
+	return nil
+}
+
+func (t *crossChainTracker) waitSourceConfirmed(ctx context.Context, hash common.Hash) error {
+	params, err := getSideChainParams(t.srcCfg)
+	if err != nil {
+		return err
+	}
+	target := t.receipt.BlockNumber.Uint64() + params.BlocksToWait
+	var height uint64
+	if err := t.poll(ctx, func() (done bool, err error) {
+		height, err = t.src.GetCurrentBlockHeight(ctx)
+		return err == nil && height >= target, err
+	}); err != nil {
+		return err
+	}
+	t.report(basedef.STATE_SOURCE_CONFIRMED, t.srcCfg.SideChainID, target, t.headerTime(ctx, t.src, target), hash.Hex())
+	return nil
+}
+
+func (t *crossChainTracker) waitPolyConfirmed(ctx context.Context, hash common.Hash) error {
+	next := t.polyStart
+	if next == 0 {
+		curr, err := t.poly.GetCurrentBlockHeight(ctx)
+		if err != nil {
+			return err
+		}
+		if curr > defaultPolyLookback {
+			next = curr - defaultPolyLookback
+		}
+	}
+	if err := t.poll(ctx, func() (bool, error) {
+		curr, err := t.poly.GetCurrentBlockHeight(ctx)
+		if err != nil || curr < next {
+			return false, err
+		}
+		t.proof, err = t.poly.FindMakeProof(ctx, t.srcCfg.SideChainID, t.event.TxId, next, curr)
+		next = curr + 1
+		return t.proof != nil, err
+	}); err != nil {
+		return err
+	}
+	var timestamp uint64
+	if block, err := t.poly.GetBlockByHeight(ctx, t.proof.Height); err == nil {
+		timestamp = uint64(block.Header.Timestamp)
+	}
+	t.report(basedef.STATE_POLY_CONFIRMED, basedef.POLY_CROSSCHAIN_ID, t.proof.Height, timestamp, t.proof.TxHash)
+	return nil
+}
+
+func (t *crossChainTracker) waitDestinationDone(ctx context.Context, hash common.Hash) (err error) {
+	if t.dstCfg, err = findChainConfig(t.event.ToChainId); err != nil {
+		log.Error("[%s] dest chain %d can not be tracked, err: %v", stateName(t.state), t.event.ToChainId, err)
+		return fmt.Errorf("dest chain %d is not configured, %v", t.event.ToChainId, err)
+	}
+	if t.dst, err = chainsdk.NewEthereumSdk(t.dstCfg.RPC); err != nil {
+		return err
+	}
+	next := t.dstStart
+	if next == 0 {
+		curr, err := t.dst.GetCurrentBlockHeight(ctx)
+		if err != nil {
+			return err
+		}
+		if curr > defaultDstLookback {
+			next = curr - defaultDstLookback
+		}
+	}
+	eccm := common.HexToAddress(t.dstCfg.ECCM)
+	if err := t.poll(ctx, func() (bool, error) {
+		curr, err := t.dst.GetCurrentBlockHeight(ctx)
+		if err != nil || curr < next {
+			return false, err
+		}
+		t.execute, err = t.dst.FindExecuteTxEvent(ctx, eccm, t.srcCfg.SideChainID, t.event.TxId, next, curr)
+		next = curr + 1
+		return t.execute != nil, err
+	}); err != nil {
+		return err
+	}
+	height := t.execute.Raw.BlockNumber
+	t.report(basedef.STATE_DESTINATION_DONE, t.dstCfg.SideChainID, height, t.headerTime(ctx, t.dst, height),
+		t.execute.Raw.TxHash.Hex())
+	return nil
+}
+
+func (t *crossChainTracker) waitFinished(ctx context.Context, hash common.Hash) error {
+	dstHash := t.execute.Raw.TxHash
+	receipt, err := t.dst.GetTransactionReceipt(ctx, dstHash)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("destination tx %s failed at height %d", dstHash.Hex(), receipt.BlockNumber)
+	}
+	t.dstTx = receipt
+	height := receipt.BlockNumber.Uint64()
+	t.report(basedef.STATE_FINISHED, t.dstCfg.SideChainID, height, t.headerTime(ctx, t.dst, height), dstHash.Hex())
+	return nil
+}
@@ -18,35 +18,221 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"poly-bridge/basedef"
 	"poly-bridge/chainsdk"
+	"poly-bridge/utils/wallet"
+	"sort"
 
+	log "github.com/astaxie/beego/logs"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	polysdk "github.com/polynetwork/poly-go-sdk"
+	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
+	nm "github.com/polynetwork/poly/native/service/governance/node_manager"
+	scm "github.com/polynetwork/poly/native/service/governance/side_chain_manager"
 	"github.com/polynetwork/poly/native/service/header_sync/bsc"
 	"github.com/polynetwork/poly/native/service/header_sync/heco"
+	polyutils "github.com/polynetwork/poly/native/service/utils"
+	"github.com/urfave/cli"
 )
 
+// okRouter is the poly router of okex chain, which is not defined in current poly version.
+const okRouter = uint64(12)
+
+type SideChainParams struct {
+	BlocksToWait    uint64
+	MinBlocksToWait uint64
+	Router          uint64
+	ExtraInfo       []byte
+}
+
+func (p *SideChainParams) String() string {
+	return fmt.Sprintf("router %d, blocksToWait %d, minBlocksToWait %d, extra %s",
+		p.Router, p.BlocksToWait, p.MinBlocksToWait, string(p.ExtraInfo))
+}
+
+// getSideChainParams returns the params used to register or update side chain on poly,
+// fields not set in chain config will be filled with default values.
+func getSideChainParams(c *ChainConfig) (*SideChainParams, error) {
+	minBlocksToWait, ok := basedef.MIN_BLOCKS_TO_WAIT[c.SideChainID]
+	if !ok {
+		return nil, fmt.Errorf("chain id %d invalid", c.SideChainID)
+	}
+
+	params := &SideChainParams{
+		BlocksToWait:    c.BlocksToWait,
+		MinBlocksToWait: minBlocksToWait,
+		Router:          c.Router,
+	}
+	if params.BlocksToWait == 0 {
+		params.BlocksToWait = minBlocksToWait
+	}
+	if c.MinBlocksToWait > params.MinBlocksToWait {
+		params.MinBlocksToWait = c.MinBlocksToWait
+	}
+	if params.Router == 0 {
+		params.Router = defaultRouter(c.SideChainID)
+	}
+
+	if len(c.ExtraInfo) > 0 && string(c.ExtraInfo) != "null" {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, c.ExtraInfo); err != nil {
+			return nil, fmt.Errorf("invalid extra info of chain %d, err: %v", c.SideChainID, err)
+		}
+		params.ExtraInfo = buf.Bytes()
+	} else if c.SideChainID == basedef.BSC_CROSSCHAIN_ID {
+		ext := bsc.ExtraInfo{
+			ChainID: new(big.Int).SetUint64(c.SideChainID),
+		}
+		extEnc, err := json.Marshal(ext)
+		if err != nil {
+			return nil, err
+		}
+		params.ExtraInfo = extEnc
+	}
+	return params, nil
+}
+
+func defaultRouter(chainID uint64) uint64 {
+	switch chainID {
+	case basedef.BSC_CROSSCHAIN_ID:
+		return polyutils.BSC_ROUTER
+	case basedef.HECO_CROSSCHAIN_ID:
+		return polyutils.HECO_ROUTER
+	case basedef.OK_CROSSCHAIN_ID:
+		return okRouter
+	}
+	return polyutils.ETH_ROUTER
+}
+
+// checkBlocksToWait refuse side chain with too few confirmations unless `force` is set.
+func checkBlocksToWait(chainID uint64, params *SideChainParams, force bool) error {
+	if params.BlocksToWait >= params.MinBlocksToWait {
+		return nil
+	}
+	if force {
+		log.Warn("chain %d blocksToWait %d is less than %d, forced to continue",
+			chainID, params.BlocksToWait, params.MinBlocksToWait)
+		return nil
+	}
+	return fmt.Errorf("chain %d blocksToWait %d is less than %d, use --%s to ignore it",
+		chainID, params.BlocksToWait, params.MinBlocksToWait, getFlagName(ForceFlag))
+}
+
+// diffSideChain compares side chain registration in poly with local chain config.
+func diffSideChain(sideChain *scm.SideChain, c *ChainConfig, params *SideChainParams) []string {
+	diffs := make([]string, 0)
+	if sideChain.ChainId != c.SideChainID {
+		diffs = append(diffs, fmt.Sprintf("chain id mismatch, poly %d, config %d", sideChain.ChainId, c.SideChainID))
+	}
+	if sideChain.Name != c.SideChainName {
+		diffs = append(diffs, fmt.Sprintf("name mismatch, poly %s, config %s", sideChain.Name, c.SideChainName))
+	}
+	if sideChain.Router != params.Router {
+		diffs = append(diffs, fmt.Sprintf("router mismatch, poly %d, config %d", sideChain.Router, params.Router))
+	}
+	if sideChain.BlocksToWait != params.BlocksToWait {
+		diffs = append(diffs, fmt.Sprintf("blocksToWait mismatch, poly %d, config %d", sideChain.BlocksToWait, params.BlocksToWait))
+	}
+	eccd := common.BytesToAddress(sideChain.CCMCAddress)
+	if eccd != common.HexToAddress(c.ECCD) {
+		diffs = append(diffs, fmt.Sprintf("eccd mismatch, poly %s, config %s", eccd.Hex(), c.ECCD))
+	}
+	if !bytes.Equal(sideChain.ExtraInfo, params.ExtraInfo) {
+		diffs = append(diffs, fmt.Sprintf("extra info mismatch, poly %s, config %s", string(sideChain.ExtraInfo), string(params.ExtraInfo)))
+	}
+	return diffs
+}
+
+func loadNodeAccount(ctx *cli.Context) (*polysdk.Account, error) {
+	path := flag2string(ctx, NodeWalletFlag)
+	if path == "" {
+		return nil, fmt.Errorf("node wallet path is empty")
+	}
+	return wallet.LoadPolyAccount(path, cfg.Poly.Passphrase)
+}
+
+// flag2peer returns the peer public key from `peer` flag, or from the node wallet if `peer` not set.
+func flag2peer(ctx *cli.Context) (string, error) {
+	if peer := flag2string(ctx, PeerFlag); peer != "" {
+		return peer, nil
+	}
+	node, err := loadNodeAccount(ctx)
+	if err != nil {
+		return "", err
+	}
+	return vconfig.PubkeyID(node.PublicKey), nil
+}
+
+func peerStatusName(status nm.Status) string {
+	switch status {
+	case nm.CandidateStatus:
+		return "candidate"
+	case nm.ConsensusStatus:
+		return "consensus"
+	case nm.QuitingStatus:
+		return "quiting"
+	case nm.BlackStatus:
+		return "black"
+	}
+	return fmt.Sprintf("unknown(%d)", status)
+}
+
+// dumpPolyPeers prints peers registered in poly node manager and the current vbft consensus set.
+func dumpPolyPeers(polySdk *chainsdk.PolySDK) error {
+	view, err := polySdk.GetGovernanceView()
+	if err != nil {
+		return err
+	}
+	peerPoolMap, err := polySdk.GetPeerPoolMap(view.View)
+	if err != nil {
+		return err
+	}
+	peers, cfgHeight, err := polySdk.GetConsensusPeers()
+	if err != nil {
+		return err
+	}
+
+	items := make([]*nm.PeerPoolItem, 0, len(peerPoolMap.PeerPoolMap))
+	for _, item := range peerPoolMap.PeerPoolMap {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Index < items[j].Index
+	})
+
+	log.Info("governance view %d, height %d, peer pool size %d", view.View, view.Height, len(items))
+	for _, item := range items {
+		log.Info("peer index %d, status %s, address %s, pubkey %s",
+			item.Index, peerStatusName(item.Status), item.Address.ToBase58(), item.PeerPubkey)
+	}
+
+	log.Info("vbft consensus set in block %d, size %d", cfgHeight, len(peers))
+	for _, peer := range peers {
+		log.Info("consensus peer index %d, pubkey %s", peer.Index, peer.ID)
+	}
+	return nil
+}
+
 func SyncEthGenesisHeader2Poly(
 	sideChainID uint64,
 	sideChainSdk *chainsdk.EthereumSdk,
 	polySdk *chainsdk.PolySDK,
 	validators []*polysdk.Account,
+	profile string,
 ) (err error) {
 
-	curr, err := sideChainSdk.GetCurrentBlockHeight()
+	curr, err := sideChainSdk.GetCurrentBlockHeight(context.Background())
 	if err != nil {
 		return err
 	}
-	hdr, err := sideChainSdk.GetHeaderByNumber(curr)
-	if err != nil {
-		return err
-	}
-
-	headerEnc, err := hdr.MarshalJSON()
+	headerEnc, _, err := genesisHeader(sideChainSdk, curr, profile)
 	if err != nil {
 		return err
 	}
@@ -58,14 +244,48 @@ func SyncEthGenesisHeader2Poly(
 	return nil
 }
 
+const (
+	headerProfileEthereum = "ethereum"
+	headerProfileRaw      = "raw"
+)
+
+// genesisHeader returns the json of header at `height` which is synced to poly as genesis header.
+// Ethereum profile re-encodes the decoded header and requires the recomputed hash to match the node's,
+// raw profile keeps the json of the node for side chains whose header has extra fields or hashes differently.
+func genesisHeader(sdk *chainsdk.EthereumSdk, height uint64, profile string) (json.RawMessage, *types.Header, error) {
+	raw, err := sdk.GetRawHeaderByNumber(context.Background(), height)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch profile {
+	case "", headerProfileEthereum:
+		if err := raw.Verify(); err != nil {
+			return nil, nil, fmt.Errorf("%v, set HeaderProfile to %s if the chain hashes header differently", err, headerProfileRaw)
+		}
+		enc, err := raw.Header.MarshalJSON()
+		if err != nil {
+			return nil, nil, err
+		}
+		return enc, raw.Header, nil
+	case headerProfileRaw:
+		if err := raw.Verify(); err != nil {
+			log.Warn("use raw header json, %v", err)
+		}
+		return raw.Raw, raw.Header, nil
+	default:
+		return nil, nil, fmt.Errorf("header profile %s invalid", profile)
+	}
+}
+
 func SyncBscGenesisHeader2Poly(
 	sideChainID uint64,
 	sideChainSdk *chainsdk.EthereumSdk,
 	polySdk *chainsdk.PolySDK,
 	validators []*polysdk.Account,
+	profile string,
 ) error {
 
-	height, err := sideChainSdk.GetCurrentBlockHeight()
+	height, err := sideChainSdk.GetCurrentBlockHeight(context.Background())
 	if err != nil {
 		return err
 	}
@@ -73,11 +293,11 @@ func SyncBscGenesisHeader2Poly(
 	epochHeight := height - height%200
 	pEpochHeight := epochHeight - 200
 
-	hdr, err := sideChainSdk.GetHeaderByNumber(epochHeight)
+	hdrEnc, hdr, err := genesisHeader(sideChainSdk, epochHeight, profile)
 	if err != nil {
 		return err
 	}
-	phdr, err := sideChainSdk.GetHeaderByNumber(pEpochHeight)
+	phdr, err := sideChainSdk.GetHeaderByNumber(context.Background(), pEpochHeight)
 	if err != nil {
 		return err
 	}
@@ -94,8 +314,11 @@ func SyncBscGenesisHeader2Poly(
 		return fmt.Errorf("invalid epoch header at height:%d", pEpochHeight)
 	}
 
-	genesisHeader := bsc.GenesisHeader{
-		Header: *hdr,
+	genesis := struct {
+		Header         json.RawMessage
+		PrevValidators []bsc.HeightAndValidators
+	}{
+		Header: hdrEnc,
 		PrevValidators: []bsc.HeightAndValidators{
 			{
 				Height:     big.NewInt(int64(pEpochHeight)),
@@ -104,7 +327,7 @@ func SyncBscGenesisHeader2Poly(
 		},
 	}
 
-	headerEnc, err := json.Marshal(genesisHeader)
+	headerEnc, err := json.Marshal(genesis)
 	if err != nil {
 		return err
 	}
@@ -121,9 +344,10 @@ func SyncHecoGenesisHeader2Poly(
 	sideChainSdk *chainsdk.EthereumSdk,
 	polySdk *chainsdk.PolySDK,
 	validators []*polysdk.Account,
+	profile string,
 ) error {
 
-	height, err := sideChainSdk.GetCurrentBlockHeight()
+	height, err := sideChainSdk.GetCurrentBlockHeight(context.Background())
 	if err != nil {
 		return err
 	}
@@ -131,11 +355,11 @@ func SyncHecoGenesisHeader2Poly(
 	epochHeight := height - height%200
 	pEpochHeight := epochHeight - 200
 
-	hdr, err := sideChainSdk.GetHeaderByNumber(epochHeight)
+	hdrEnc, hdr, err := genesisHeader(sideChainSdk, epochHeight, profile)
 	if err != nil {
 		return err
 	}
-	phdr, err := sideChainSdk.GetHeaderByNumber(pEpochHeight)
+	phdr, err := sideChainSdk.GetHeaderByNumber(context.Background(), pEpochHeight)
 	if err != nil {
 		return err
 	}
@@ -151,8 +375,11 @@ func SyncHecoGenesisHeader2Poly(
 		return fmt.Errorf("invalid epoch header at height:%d", pEpochHeight)
 	}
 
-	genesisHeader := bsc.GenesisHeader{
-		Header: *hdr,
+	genesis := struct {
+		Header         json.RawMessage
+		PrevValidators []bsc.HeightAndValidators
+	}{
+		Header: hdrEnc,
 		PrevValidators: []bsc.HeightAndValidators{
 			{
 				Height:     big.NewInt(int64(pEpochHeight)),
@@ -160,7 +387,7 @@ func SyncHecoGenesisHeader2Poly(
 			},
 		},
 	}
-	headerEnc, err := json.Marshal(genesisHeader)
+	headerEnc, err := json.Marshal(genesis)
 	if err != nil {
 		return err
 	}
@@ -216,27 +443,34 @@ func SyncOKGenesisHeader2Poly(
 	return nil
 }
 
-func SyncPolyGenesisHeader2Eth(
-	polySDK *chainsdk.PolySDK,
-	sideChainECCMOwnerKey *ecdsa.PrivateKey,
-	sideChainSdk *chainsdk.EthereumSdk,
-	sideChainECCM common.Address,
-) error {
-
+// polyGenesisHeader returns the poly header and bookkeepers used to init genesis block of eccm.
+func polyGenesisHeader(polySDK *chainsdk.PolySDK) (header, bookkeepers []byte, err error) {
 	// `epoch` related with the poly validators changing,
 	// we can set it as 0 if poly validators never changed on develop environment.
 	var RCEpoch uint64 = 0
-	gB, err := polySDK.GetBlockByHeight(RCEpoch)
+	gB, err := polySDK.GetBlockByHeight(context.Background(), RCEpoch)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	bookeepers, err := chainsdk.GetBookeeper(gB)
+	if err != nil {
+		return nil, nil, err
+	}
+	return gB.Header.ToArray(), chainsdk.AssembleNoCompressBookeeper(bookeepers), nil
+}
+
+func SyncPolyGenesisHeader2Eth(
+	polySDK *chainsdk.PolySDK,
+	sideChainECCMOwnerKey *ecdsa.PrivateKey,
+	sideChainSdk *chainsdk.EthereumSdk,
+	sideChainECCM common.Address,
+) error {
+
+	headerEnc, bookeepersEnc, err := polyGenesisHeader(polySDK)
 	if err != nil {
 		return err
 	}
-	bookeepersEnc := chainsdk.AssembleNoCompressBookeeper(bookeepers)
-	headerEnc := gB.Header.ToArray()
 
 	if _, err := sideChainSdk.InitGenesisBlock(
 		sideChainECCMOwnerKey,
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"text/tabwriter"
+
+	"poly-bridge/chainsdk"
+	"poly-bridge/go_abi/eccd_abi"
+	"poly-bridge/go_abi/eccm_abi"
+	"poly-bridge/go_abi/eccmp_abi"
+	"poly-bridge/go_abi/lock_proxy_abi"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type codeCheckResult struct {
+	Chain    uint64              `json:"chain"`
+	Contract string              `json:"contract"`
+	Address  string              `json:"address"`
+	Status   chainsdk.CodeStatus `json:"status"`
+	Detail   string              `json:"detail"`
+}
+
+type verifyCodeReport struct {
+	Items      []*codeCheckResult `json:"items"`
+	Mismatches int                `json:"mismatches"`
+}
+
+func (r *verifyCodeReport) table() string {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHAIN\tCONTRACT\tADDRESS\tSTATUS\tDETAIL")
+	for _, item := range r.Items {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", item.Chain, item.Contract, item.Address, item.Status, item.Detail)
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// verifyChainCode compares code of contracts configured on chain `c` with the bindings, eccm and ccmp
+// are constructed with the configured eccd and eccm, as they're deployed by this tool.
+func verifyChainCode(ctx context.Context, c *ChainConfig) ([]*codeCheckResult, error) {
+	s, release, err := chainSdk(c)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	eccd, eccm := common.HexToAddress(c.ECCD), common.HexToAddress(c.ECCM)
+	contracts := []struct {
+		name, address, abi, bin string
+		args                    []interface{}
+	}{
+		{"eccd", c.ECCD, eccd_abi.EthCrossChainDataABI, eccd_abi.EthCrossChainDataBin, nil},
+		{"eccm", c.ECCM, eccm_abi.EthCrossChainManagerABI, eccm_abi.EthCrossChainManagerBin,
+			[]interface{}{eccd, c.SideChainID}},
+		{"ccmp", c.CCMP, eccmp_abi.EthCrossChainManagerProxyABI, eccmp_abi.EthCrossChainManagerProxyBin,
+			[]interface{}{eccm}},
+		{"lockProxy", c.LockProxy, lock_proxy_abi.LockProxyABI, lock_proxy_abi.LockProxyBin, nil},
+	}
+
+	results := make([]*codeCheckResult, 0, len(contracts))
+	for _, contract := range contracts {
+		if contract.address == "" {
+			continue
+		}
+		item := &codeCheckResult{Chain: c.SideChainID, Contract: contract.name, Address: contract.address}
+		results = append(results, item)
+		expected, err := chainsdk.RuntimeCode(contract.abi, contract.bin, contract.args...)
+		if err != nil {
+			return nil, fmt.Errorf("runtime code of %s err: %v", contract.name, err)
+		}
+		deployed, err := s.CodeAt(ctx, common.HexToAddress(contract.address))
+		if err != nil {
+			return nil, fmt.Errorf("get code of %s on chain %d err: %v", contract.name, c.SideChainID, err)
+		}
+		item.Status, item.Detail = chainsdk.CompareCode(deployed, expected)
+	}
+	return results, nil
+}
+
+// verifyCode compares code of contracts configured on all chains with the bindings in go_abi, code
+// with only metadata differs is regarded as matched.
+func verifyCode(ctx context.Context) (*verifyCodeReport, error) {
+	report := &verifyCodeReport{Items: make([]*codeCheckResult, 0)}
+	for _, c := range []*ChainConfig{cfg.Ethereum, cfg.Bsc, cfg.Heco, cfg.Ok} {
+		if c == nil {
+			continue
+		}
+		results, err := verifyChainCode(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range results {
+			if item.Status != chainsdk.CodeMatch && item.Status != chainsdk.CodeMetadataDiffers {
+				report.Mismatches++
+			}
+		}
+		report.Items = append(report.Items, results...)
+	}
+	return report, nil
+}
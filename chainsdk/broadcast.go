@@ -0,0 +1,194 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/astaxie/beego/logs"
+)
+
+// TxStatus denotes the answer of a node to a broadcast transaction.
+type TxStatus int
+
+const (
+	TxAccepted TxStatus = iota
+	TxAlreadyKnown
+	TxUnderpriced
+	TxNonceTooLow
+	TxInsufficientFunds
+	TxRejected
+	TxNodeFailed
+)
+
+func (s TxStatus) String() string {
+	switch s {
+	case TxAccepted:
+		return "accepted"
+	case TxAlreadyKnown:
+		return "already known"
+	case TxUnderpriced:
+		return "underpriced"
+	case TxNonceTooLow:
+		return "nonce too low"
+	case TxInsufficientFunds:
+		return "insufficient funds"
+	case TxRejected:
+		return "rejected"
+	case TxNodeFailed:
+		return "node failed"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// ErrTxRejected is returned when the node refused the transaction without any reason.
+var ErrTxRejected = errors.New("transaction rejected")
+
+// ClassifyTxError classifies the error answered by a node which the transaction sent to, the
+// messages of geth, bsc, heco, okexchain and neo nodes are recognized.
+func ClassifyTxError(err error) TxStatus {
+	if err == nil {
+		return TxAccepted
+	}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "already known"),
+		strings.Contains(msg, "known transaction"),
+		strings.Contains(msg, "already imported"),
+		strings.Contains(msg, "already exist"):
+		return TxAlreadyKnown
+	case strings.Contains(msg, "underpriced"),
+		strings.Contains(msg, "fee too low"):
+		return TxUnderpriced
+	case strings.Contains(msg, "nonce too low"):
+		return TxNonceTooLow
+	case strings.Contains(msg, "insufficient funds"):
+		return TxInsufficientFunds
+	default:
+		return TxRejected
+	}
+}
+
+type TxSendResult struct {
+	Url     string
+	Status  TxStatus
+	Err     error
+	Latency time.Duration
+}
+
+// TxSendError is returned if no node accepted the broadcast transaction, the status is the answer of
+// working nodes if any, otherwise TxNodeFailed.
+type TxSendError struct {
+	Status TxStatus
+	Err    error
+}
+
+func (e *TxSendError) Error() string {
+	return fmt.Sprintf("transaction %s: %v", e.Status, e.Err)
+}
+
+// BroadcastResult contains answers of all nodes which the transaction broadcast to.
+type BroadcastResult []*TxSendResult
+
+// Accepted reports whether any node accepted the transaction, a transaction already known by the
+// node is regarded as accepted.
+func (r BroadcastResult) Accepted() bool {
+	for _, res := range r {
+		if res.Status == TxAccepted || res.Status == TxAlreadyKnown {
+			return true
+		}
+	}
+	return false
+}
+
+// Err returns nil if the transaction is accepted, otherwise the answer of a working node is preferred.
+func (r BroadcastResult) Err() error {
+	if r.Accepted() {
+		return nil
+	}
+	var failed *TxSendResult
+	for _, res := range r {
+		if res.Status != TxNodeFailed {
+			return &TxSendError{Status: res.Status, Err: res.Err}
+		}
+		failed = res
+	}
+	if failed == nil {
+		return &TxSendError{Status: TxNodeFailed, Err: fmt.Errorf("all node is not working")}
+	}
+	return &TxSendError{Status: TxNodeFailed, Err: failed.Err}
+}
+
+// selectNodes returns all nodes which are not cut off and not lag behind the best node too much.
+func (pool *Pool) selectNodes() []*poolNode {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	now := time.Now()
+	best := uint64(0)
+	for _, n := range pool.nodes {
+		if n.available(now) && n.latestHeight > best {
+			best = n.latestHeight
+		}
+	}
+	nodes := make([]*poolNode, 0, len(pool.nodes))
+	for _, n := range pool.nodes {
+		if n.available(now) && n.latestHeight+pool.lagTolerance >= best {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// Broadcast invokes `send` with every healthy node in parallel and classifies the answers, node
+// health is updated by the answers as Call does.
+func (pool *Pool) Broadcast(ctx context.Context, send func(node PoolNode) error) BroadcastResult {
+	nodes := pool.selectNodes()
+	results := make(BroadcastResult, len(nodes))
+	wg := sync.WaitGroup{}
+	for i, n := range nodes {
+		wg.Add(1)
+		go func(i int, n *poolNode) {
+			defer wg.Done()
+			start := time.Now()
+			err := send(n.node)
+			res := &TxSendResult{Url: n.url, Status: ClassifyTxError(err), Err: err, Latency: time.Since(start)}
+			switch {
+			case err != nil && ctx.Err() != nil:
+				res.Status = TxNodeFailed
+			case err != nil && n.node.IsNodeError(err):
+				res.Status = TxNodeFailed
+				pool.markFailure(n, res.Latency)
+			default:
+				pool.markSuccess(n, res.Latency, 0)
+			}
+			if err != nil {
+				logs.Debug("broadcast to node %s of chain %d, status: %s, err: %v", n.url, pool.id, res.Status, err)
+			}
+			results[i] = res
+		}(i, n)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,73 @@
+package chainsdk
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestClassifyTxError(t *testing.T) {
+	cases := []struct {
+		err    error
+		status TxStatus
+	}{
+		{nil, TxAccepted},
+		{fmt.Errorf("already known"), TxAlreadyKnown},
+		{fmt.Errorf("known transaction: 0x1234"), TxAlreadyKnown},
+		{fmt.Errorf("Block or transaction already exists and cannot be sent repeatedly."), TxAlreadyKnown},
+		{fmt.Errorf("transaction underpriced"), TxUnderpriced},
+		{fmt.Errorf("replacement transaction underpriced"), TxUnderpriced},
+		{fmt.Errorf("nonce too low"), TxNonceTooLow},
+		{fmt.Errorf("insufficient funds for gas * price + value"), TxInsufficientFunds},
+		{fmt.Errorf("exceeds block gas limit"), TxRejected},
+		{ErrTxRejected, TxRejected},
+	}
+	for _, c := range cases {
+		if status := ClassifyTxError(c.err); status != c.status {
+			t.Fatalf("classify %v, expected %s, got %s", c.err, c.status, status)
+		}
+	}
+}
+
+func TestPool_Broadcast(t *testing.T) {
+	a := &fakeNode{height: 100}
+	b := &fakeNode{height: 100}
+	c := &fakeNode{height: 100 - DefaultLagTolerance - 1}
+	pool := newTestPool(t, map[string]*fakeNode{"a": a, "b": b, "c": c})
+
+	sent := make(chan PoolNode, 3)
+	result := pool.Broadcast(context.Background(), func(node PoolNode) error {
+		sent <- node
+		if node == PoolNode(a) {
+			return errAnswered
+		}
+		return nil
+	})
+	close(sent)
+	if len(sent) != 2 || len(result) != 2 {
+		t.Fatalf("transaction should be broadcast to nodes within lag tolerance, sent %d", len(sent))
+	}
+	if !result.Accepted() || result.Err() != nil {
+		t.Fatalf("transaction should be accepted, err: %v", result.Err())
+	}
+
+	b.setFailing(true)
+	result = pool.Broadcast(context.Background(), func(node PoolNode) error {
+		if node == PoolNode(a) {
+			return errAnswered
+		}
+		return node.(*fakeNode).call()
+	})
+	err, ok := result.Err().(*TxSendError)
+	if !ok || err.Status != TxRejected || err.Err != errAnswered {
+		t.Fatalf("answer of working node should be returned, got %v", result.Err())
+	}
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	for _, n := range pool.nodes {
+		if n.url == "a" && n.failures != 0 || n.url == "b" && n.failures != 1 {
+			t.Fatalf("node %s failures unexpected: %d", n.url, n.failures)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	pcom "github.com/polynetwork/poly-go-sdk/common"
+	scom "github.com/polynetwork/poly/native/service/cross_chain_manager/common"
+	"github.com/polynetwork/poly/native/service/utils"
+	"poly-bridge/go_abi/eccm_abi"
+	"poly-bridge/go_abi/lock_proxy_abi"
+)
+
+// MaxFilterRange limits the number of blocks in one eth_getLogs request.
+var MaxFilterRange = uint64(2000)
+
+var eccmABI, lockProxyABI abi.ABI
+
+func init() {
+	var err error
+	if eccmABI, err = abi.JSON(strings.NewReader(eccm_abi.EthCrossChainManagerABI)); err != nil {
+		panic(err)
+	}
+	if lockProxyABI, err = abi.JSON(strings.NewReader(lock_proxy_abi.LockProxyABI)); err != nil {
+		panic(err)
+	}
+}
+
+// PolyMakeProof is the makeProof notify of poly cross chain manager, it means the cross chain tx of
+// source chain is verified by poly and relayers can commit it to the destination chain.
+type PolyMakeProof struct {
+	TxHash      string
+	Height      uint64
+	FromChainID uint64
+	ToChainID   uint64
+	SrcTxID     string
+	Key         string
+}
+
+// parseMakeProof returns makeProof notifies in poly smart contract event.
+func parseMakeProof(event *pcom.SmartContactEvent) []*PolyMakeProof {
+	ccm := utils.CrossChainManagerContractAddress.ToHexString()
+	proofs := make([]*PolyMakeProof, 0)
+	for _, notify := range event.Notify {
+		if notify.ContractAddress != ccm {
+			continue
+		}
+		states, ok := notify.States.([]interface{})
+		if !ok || len(states) < 6 {
+			continue
+		}
+		method, _ := states[0].(string)
+		fromChainID, ok1 := states[1].(float64)
+		toChainID, ok2 := states[2].(float64)
+		srcTxID, ok3 := states[3].(string)
+		height, ok4 := states[4].(float64)
+		key, _ := states[5].(string)
+		if method != scom.NOTIFY_MAKE_PROOF || !ok1 || !ok2 || !ok3 || !ok4 {
+			continue
+		}
+		proofs = append(proofs, &PolyMakeProof{
+			TxHash:      event.TxHash,
+			Height:      uint64(height),
+			FromChainID: uint64(fromChainID),
+			ToChainID:   uint64(toChainID),
+			SrcTxID:     srcTxID,
+			Key:         key,
+		})
+	}
+	return proofs
+}
+
+// FindMakeProof scans poly blocks in [start, end] for the makeProof of cross chain tx `srcTxID` from
+// chain `fromChainID`, nil returned if it's not found.
+func (sdk *PolySDK) FindMakeProof(ctx context.Context, fromChainID uint64, srcTxID []byte, start, end uint64) (*PolyMakeProof, error) {
+	id := hex.EncodeToString(srcTxID)
+	for height := start; height <= end; height++ {
+		events, err := sdk.GetSmartContractEventByBlock(ctx, height)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range events {
+			for _, proof := range parseMakeProof(event) {
+				if proof.FromChainID == fromChainID && strings.EqualFold(proof.SrcTxID, id) {
+					return proof, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// GetCrossChainEvents returns CrossChainEvent emitted by eccm in receipt.
+func GetCrossChainEvents(eccm common.Address, receipt *types.Receipt) ([]*eccm_abi.EthCrossChainManagerCrossChainEvent, error) {
+	filterer, err := eccm_abi.NewEthCrossChainManagerFilterer(eccm, nil)
+	if err != nil {
+		return nil, err
+	}
+	topic := eccmABI.Events["CrossChainEvent"].ID
+	events := make([]*eccm_abi.EthCrossChainManagerCrossChainEvent, 0)
+	for _, log := range receipt.Logs {
+		if log.Address != eccm || len(log.Topics) == 0 || log.Topics[0] != topic {
+			continue
+		}
+		evt, err := filterer.ParseCrossChainEvent(*log)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, evt)
+	}
+	return events, nil
+}
+
+// FindExecuteTxEvent filters VerifyHeaderAndExecuteTxEvent of eccm in blocks [start, end] for the cross
+// chain tx `srcTxID` from chain `fromChainID`, nil returned if it's not found.
+func (ec *EthereumSdk) FindExecuteTxEvent(ctx context.Context, eccm common.Address, fromChainID uint64, srcTxID []byte,
+	start, end uint64) (*eccm_abi.EthCrossChainManagerVerifyHeaderAndExecuteTxEvent, error) {
+	filterer, err := eccm_abi.NewEthCrossChainManagerFilterer(eccm, ec.rawClient)
+	if err != nil {
+		return nil, err
+	}
+	topic := eccmABI.Events["VerifyHeaderAndExecuteTxEvent"].ID
+	for from := start; from <= end; from += MaxFilterRange {
+		to := from + MaxFilterRange - 1
+		if to > end {
+			to = end
+		}
+		logs, err := ec.rawClient.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{eccm},
+			Topics:    [][]common.Hash{{topic}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, log := range logs {
+			evt, err := filterer.ParseVerifyHeaderAndExecuteTxEvent(log)
+			if err != nil {
+				return nil, err
+			}
+			if evt.FromChainID == fromChainID && bytes.Equal(evt.FromChainTxHash, srcTxID) {
+				return evt, nil
+			}
+		}
+	}
+	return nil, nil
+}
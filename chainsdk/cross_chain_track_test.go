@@ -0,0 +1,81 @@
+package chainsdk
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	pcom "github.com/polynetwork/poly-go-sdk/common"
+	"github.com/polynetwork/poly/native/service/utils"
+)
+
+func TestParseMakeProof(t *testing.T) {
+	ccm := utils.CrossChainManagerContractAddress.ToHexString()
+	raw := `{"TxHash":"aa","State":1,"Notify":[
+		{"ContractAddress":"` + utils.HeaderSyncContractAddress.ToHexString() + `","States":["makeProof",2,6,"0102",100,"key"]},
+		{"ContractAddress":"` + ccm + `","States":["btcTxToRelay",2,6,"0102",100,"key"]},
+		{"ContractAddress":"` + ccm + `","States":["makeProof",2,6,"0102",100,"key"]}
+	]}`
+	event := new(pcom.SmartContactEvent)
+	if err := json.Unmarshal([]byte(raw), event); err != nil {
+		t.Fatal(err)
+	}
+	proofs := parseMakeProof(event)
+	if len(proofs) != 1 {
+		t.Fatalf("only makeProof of cross chain manager expected, got %d", len(proofs))
+	}
+	proof := proofs[0]
+	if proof.TxHash != "aa" || proof.FromChainID != 2 || proof.ToChainID != 6 || proof.SrcTxID != "0102" || proof.Height != 100 {
+		t.Fatalf("unexpected proof %+v", proof)
+	}
+}
+
+func TestGetCrossChainEvents(t *testing.T) {
+	eccm := common.HexToAddress("0x0e")
+	crossChainEvent := eccmABI.Events["CrossChainEvent"]
+	data, err := crossChainEvent.Inputs.NonIndexed().Pack([]byte{1, 2}, common.HexToAddress("0x0a"), uint64(6),
+		[]byte{3}, []byte{4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := common.BytesToHash(common.HexToAddress("0x0b").Bytes())
+	receipt := &types.Receipt{Logs: []*types.Log{
+		{Address: common.HexToAddress("0x0f"), Topics: []common.Hash{crossChainEvent.ID, sender}, Data: data},
+		{Address: eccm, Topics: []common.Hash{eccmABI.Events["Paused"].ID}},
+		{Address: eccm, Topics: []common.Hash{crossChainEvent.ID, sender}, Data: data},
+	}}
+
+	events, err := GetCrossChainEvents(eccm, receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("one event of eccm expected, got %d", len(events))
+	}
+	if evt := events[0]; evt.ToChainId != 6 || common.Bytes2Hex(evt.TxId) != "0102" || evt.Sender != common.HexToAddress("0x0b") {
+		t.Fatalf("unexpected event %+v", evt)
+	}
+}
+
+func TestGetUnlockEvents(t *testing.T) {
+	proxy := common.HexToAddress("0x0e")
+	unlockEvent := lockProxyABI.Events["UnlockEvent"]
+	data, err := unlockEvent.Inputs.Pack(common.HexToAddress("0x0a"), common.HexToAddress("0x0b"), big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := &types.Receipt{Logs: []*types.Log{
+		{Address: common.HexToAddress("0x0f"), Topics: []common.Hash{unlockEvent.ID}, Data: data},
+		{Address: proxy, Topics: []common.Hash{unlockEvent.ID}, Data: data},
+	}}
+
+	events, err := GetUnlockEvents(proxy, receipt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].ToAddress != common.HexToAddress("0x0b") || events[0].Amount.Int64() != 100 {
+		t.Fatalf("unexpected events %+v", events)
+	}
+}
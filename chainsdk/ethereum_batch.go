@@ -0,0 +1,227 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// MaxBatchSize limits the number of requests in one json rpc batch or multicall, larger requests
+// are split into several round trips.
+var MaxBatchSize = 100
+
+// ErrCallFailed is set to BatchCall.Err if the call reverted inside multicall.
+var ErrCallFailed = errors.New("call failed in multicall")
+
+// multicallABI is the abi of Multicall2.tryAggregate, see github.com/makerdao/multicall.
+const multicallABI = `[{"inputs":[{"internalType":"bool","name":"requireSuccess","type":"bool"},{"components":[{"internalType":"address","name":"target","type":"address"},{"internalType":"bytes","name":"callData","type":"bytes"}],"internalType":"struct Multicall2.Call[]","name":"calls","type":"tuple[]"}],"name":"tryAggregate","outputs":[{"components":[{"internalType":"bool","name":"success","type":"bool"},{"internalType":"bytes","name":"returnData","type":"bytes"}],"internalType":"struct Multicall2.Result[]","name":"returnData","type":"tuple[]"}],"stateMutability":"nonpayable","type":"function"}]`
+
+var multicall abi.ABI
+
+func init() {
+	var err error
+	if multicall, err = abi.JSON(strings.NewReader(multicallABI)); err != nil {
+		panic(err)
+	}
+}
+
+// BatchCall is a contract call in batch, Result or Err is set after the batch done.
+type BatchCall struct {
+	To     common.Address
+	Data   []byte
+	Result []byte
+	Err    error
+}
+
+// NewBatchCall packs the contract method call with `contractABI`.
+func NewBatchCall(contractABI abi.ABI, to common.Address, method string, args ...interface{}) (*BatchCall, error) {
+	data, err := contractABI.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pack %s err: %v", method, err)
+	}
+	return &BatchCall{To: to, Data: data}, nil
+}
+
+// Unpack decodes the result of the call into `out`.
+func (c *BatchCall) Unpack(contractABI abi.ABI, method string, out interface{}) error {
+	if c.Err != nil {
+		return c.Err
+	}
+	if len(c.Result) == 0 {
+		return fmt.Errorf("call %s of %s returns nothing", method, c.To.Hex())
+	}
+	return contractABI.Unpack(out, method, c.Result)
+}
+
+func batchChunks(size int, fn func(start, end int) error) error {
+	for start := 0; start < size; start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > size {
+			end = size
+		}
+		if err := fn(start, end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchCallContract sends eth_call of all calls at latest block in json rpc batches, the error
+// returned is the transport error, errors of each call are set to BatchCall.Err.
+func (ec *EthereumSdk) BatchCallContract(ctx context.Context, calls []*BatchCall) error {
+	return batchChunks(len(calls), func(start, end int) error {
+		elems := make([]rpc.BatchElem, 0, end-start)
+		results := make([]hexutil.Bytes, end-start)
+		for i, call := range calls[start:end] {
+			arg := map[string]interface{}{"to": call.To, "data": hexutil.Bytes(call.Data)}
+			elems = append(elems, rpc.BatchElem{
+				Method: "eth_call",
+				Args:   []interface{}{arg, "latest"},
+				Result: &results[i],
+			})
+		}
+		if err := ec.rpcClient.BatchCallContext(ctx, elems); err != nil {
+			return err
+		}
+		for i, call := range calls[start:end] {
+			call.Result, call.Err = results[i], elems[i].Error
+		}
+		return nil
+	})
+}
+
+// BatchBalanceAt returns native balances of the accounts at latest block.
+func (ec *EthereumSdk) BatchBalanceAt(ctx context.Context, accounts []common.Address) ([]*big.Int, error) {
+	balances := make([]*big.Int, len(accounts))
+	err := batchChunks(len(accounts), func(start, end int) error {
+		elems := make([]rpc.BatchElem, 0, end-start)
+		results := make([]hexutil.Big, end-start)
+		for i, account := range accounts[start:end] {
+			elems = append(elems, rpc.BatchElem{
+				Method: "eth_getBalance",
+				Args:   []interface{}{account, "latest"},
+				Result: &results[i],
+			})
+		}
+		if err := ec.rpcClient.BatchCallContext(ctx, elems); err != nil {
+			return err
+		}
+		for i, elem := range elems {
+			if elem.Error != nil {
+				return fmt.Errorf("get balance of %s err: %v", accounts[start+i].Hex(), elem.Error)
+			}
+			balances[start+i] = (*big.Int)(&results[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// BatchTransactionReceipt returns receipts of the transactions, the receipt is nil if the
+// transaction is not packed yet.
+func (ec *EthereumSdk) BatchTransactionReceipt(ctx context.Context, hashes []common.Hash) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(hashes))
+	err := batchChunks(len(hashes), func(start, end int) error {
+		elems := make([]rpc.BatchElem, 0, end-start)
+		for i, hash := range hashes[start:end] {
+			elems = append(elems, rpc.BatchElem{
+				Method: "eth_getTransactionReceipt",
+				Args:   []interface{}{hash},
+				Result: &receipts[start+i],
+			})
+		}
+		if err := ec.rpcClient.BatchCallContext(ctx, elems); err != nil {
+			return err
+		}
+		for i, elem := range elems {
+			if elem.Error != nil {
+				return fmt.Errorf("get receipt of %s err: %v", hashes[start+i].Hex(), elem.Error)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// Multicall aggregates the calls into Multicall2.tryAggregate of contract `multicallAddr`, calls
+// reverted are marked with ErrCallFailed.
+func (ec *EthereumSdk) Multicall(ctx context.Context, multicallAddr common.Address, calls []*BatchCall) error {
+	type call struct {
+		Target   common.Address
+		CallData []byte
+	}
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	return batchChunks(len(calls), func(start, end int) error {
+		args := make([]call, 0, end-start)
+		for _, c := range calls[start:end] {
+			args = append(args, call{Target: c.To, CallData: c.Data})
+		}
+		data, err := multicall.Pack("tryAggregate", false, args)
+		if err != nil {
+			return err
+		}
+		var ret hexutil.Bytes
+		arg := map[string]interface{}{"to": multicallAddr, "data": hexutil.Bytes(data)}
+		if err := ec.rpcClient.CallContext(ctx, &ret, "eth_call", arg, "latest"); err != nil {
+			return err
+		}
+		var results []result
+		if err := multicall.Unpack(&results, "tryAggregate", ret); err != nil {
+			return fmt.Errorf("unpack multicall result err: %v", err)
+		}
+		if len(results) != end-start {
+			return fmt.Errorf("multicall returns %d results, expect %d", len(results), end-start)
+		}
+		for i, c := range calls[start:end] {
+			if results[i].Success {
+				c.Result, c.Err = results[i].ReturnData, nil
+			} else {
+				c.Result, c.Err = nil, ErrCallFailed
+			}
+		}
+		return nil
+	})
+}
+
+// AggregateCall executes calls with multicall if `multicallAddr` is set, otherwise falls back to
+// json rpc batch.
+func (ec *EthereumSdk) AggregateCall(ctx context.Context, multicallAddr common.Address, calls []*BatchCall) error {
+	if multicallAddr == EmptyAddress {
+		return ec.BatchCallContract(ctx, calls)
+	}
+	return ec.Multicall(ctx, multicallAddr, calls)
+}
@@ -0,0 +1,296 @@
+package chainsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"poly-bridge/go_abi/eccd_abi"
+)
+
+var testMulticallAddr = common.HexToAddress("0x00000000000000000000000000000000000000ca")
+
+// batchStub answers json rpc batches, owner() of contract is the contract address itself, the lock
+// proxy bindings are the address of lock proxy and calls to the zero address fail. gas estimated is
+// 21000 plus the length of data.
+type batchStub struct {
+	server *httptest.Server
+	trips  int64
+}
+
+type stubRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+func newBatchStub(t *testing.T) *batchStub {
+	stub := &batchStub{}
+	stub.server = httptest.NewServer(http.HandlerFunc(stub.serve))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func (stub *batchStub) serve(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&stub.trips, 1)
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+		var reqs []*stubRequest
+		json.Unmarshal(body, &reqs)
+		resps := make([]json.RawMessage, 0, len(reqs))
+		for _, req := range reqs {
+			resps = append(resps, stub.answer(req))
+		}
+		json.NewEncoder(w).Encode(resps)
+		return
+	}
+	req := new(stubRequest)
+	json.Unmarshal(body, req)
+	w.Write(stub.answer(req))
+}
+
+func (stub *batchStub) answer(req *stubRequest) json.RawMessage {
+	var result interface{}
+	switch req.Method {
+	case "eth_getBalance":
+		var account common.Address
+		json.Unmarshal(req.Params[0], &account)
+		result = hexutil.EncodeBig(new(big.Int).SetBytes(account[18:]))
+	case "eth_getTransactionReceipt":
+		var hash common.Hash
+		json.Unmarshal(req.Params[0], &hash)
+		if hash != (common.Hash{}) {
+			result = map[string]interface{}{
+				"status":            "0x1",
+				"cumulativeGasUsed": "0x5208",
+				"gasUsed":           "0x5208",
+				"logsBloom":         hexutil.Bytes(make([]byte, 256)),
+				"logs":              []interface{}{},
+				"transactionHash":   hash,
+				"blockNumber":       "0x10",
+			}
+		}
+	case "eth_estimateGas":
+		var msg struct {
+			Data hexutil.Bytes `json:"data"`
+		}
+		json.Unmarshal(req.Params[0], &msg)
+		result = hexutil.Uint64(21000 + len(msg.Data))
+	case "eth_call":
+		var msg struct {
+			To   common.Address `json:"to"`
+			Data hexutil.Bytes  `json:"data"`
+		}
+		json.Unmarshal(req.Params[0], &msg)
+		if msg.To == testMulticallAddr {
+			result = hexutil.Bytes(stubMulticall(msg.Data))
+		} else if msg.To == (common.Address{}) {
+			return json.RawMessage(fmt.Sprintf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":"execution reverted"}}`, req.ID))
+		} else {
+			result = hexutil.Bytes(stubCall(msg.To, msg.Data))
+		}
+	}
+	resp, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	return resp
+}
+
+func stubCall(to common.Address, data []byte) []byte {
+	if len(data) >= 4 && string(data[:4]) == string(erc20Meta.Methods["symbol"].ID) {
+		out, err := erc20Meta.Methods["symbol"].Outputs.Pack("T" + to.Hex()[40:])
+		if err != nil {
+			panic(err)
+		}
+		return out
+	}
+	for _, name := range []string{"proxyHashMap", "assetHashMap"} {
+		method := lockProxyABI.Methods[name]
+		if len(data) >= 4 && string(data[:4]) == string(method.ID) {
+			out, err := method.Outputs.Pack(to.Bytes())
+			if err != nil {
+				panic(err)
+			}
+			return out
+		}
+	}
+	return common.LeftPadBytes(to.Bytes(), 32)
+}
+
+func stubMulticall(data []byte) []byte {
+	var in struct {
+		RequireSuccess bool
+		Calls          []struct {
+			Target   common.Address
+			CallData []byte
+		}
+	}
+	method := multicall.Methods["tryAggregate"]
+	if err := method.Inputs.Unpack(&in, data[4:]); err != nil {
+		panic(err)
+	}
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	results := make([]result, 0, len(in.Calls))
+	for _, call := range in.Calls {
+		if call.Target == (common.Address{}) {
+			results = append(results, result{Success: false, ReturnData: []byte{}})
+		} else {
+			results = append(results, result{Success: true, ReturnData: stubCall(call.Target, call.CallData)})
+		}
+	}
+	out, err := method.Outputs.Pack(results)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
+func newBatchTestSdk(t *testing.T) (*EthereumSdk, *batchStub) {
+	stub := newBatchStub(t)
+	sdk, err := NewEthereumSdk(stub.server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(sdk.Close)
+	return sdk, stub
+}
+
+func TestEthereumSdk_BatchBalanceAt(t *testing.T) {
+	sdk, stub := newBatchTestSdk(t)
+	defer func(size int) { MaxBatchSize = size }(MaxBatchSize)
+	MaxBatchSize = 2
+
+	accounts := []common.Address{
+		common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x0100"),
+	}
+	balances, err := sdk.BatchBalanceAt(context.Background(), accounts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expect := range []int64{1, 2, 256} {
+		if balances[i].Int64() != expect {
+			t.Fatalf("balance of %s expected %d, got %s", accounts[i].Hex(), expect, balances[i])
+		}
+	}
+	if trips := atomic.LoadInt64(&stub.trips); trips != 2 {
+		t.Fatalf("batch should be split into 2 round trips, got %d", trips)
+	}
+}
+
+func TestEthereumSdk_BatchTransactionReceipt(t *testing.T) {
+	sdk, _ := newBatchTestSdk(t)
+	hashes := []common.Hash{common.HexToHash("0x01"), {}}
+	receipts, err := sdk.BatchTransactionReceipt(context.Background(), hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipts[0] == nil || receipts[0].Status != 1 || receipts[0].BlockNumber.Uint64() != 16 {
+		t.Fatalf("unexpected receipt %+v", receipts[0])
+	}
+	if receipts[1] != nil {
+		t.Fatalf("receipt of pending transaction should be nil")
+	}
+}
+
+func TestEthereumSdk_AggregateCall(t *testing.T) {
+	sdk, stub := newBatchTestSdk(t)
+	ownable, err := abi.JSON(strings.NewReader(eccd_abi.OwnableABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contracts := []common.Address{common.HexToAddress("0x0a"), {}, common.HexToAddress("0x0b")}
+
+	for _, multicallAddr := range []common.Address{EmptyAddress, testMulticallAddr} {
+		trips := atomic.LoadInt64(&stub.trips)
+		calls := make([]*BatchCall, 0, len(contracts))
+		for _, contract := range contracts {
+			call, err := NewBatchCall(ownable, contract, "owner")
+			if err != nil {
+				t.Fatal(err)
+			}
+			calls = append(calls, call)
+		}
+		if err := sdk.AggregateCall(context.Background(), multicallAddr, calls); err != nil {
+			t.Fatal(err)
+		}
+		if n := atomic.LoadInt64(&stub.trips) - trips; n != 1 {
+			t.Fatalf("calls should be sent in one round trip, got %d", n)
+		}
+
+		var owner common.Address
+		if err := calls[0].Unpack(ownable, "owner", &owner); err != nil || owner != contracts[0] {
+			t.Fatalf("owner expected %s, got %s, err: %v", contracts[0].Hex(), owner.Hex(), err)
+		}
+		if err := calls[1].Unpack(ownable, "owner", &owner); err == nil {
+			t.Fatalf("failed call should return error")
+		}
+		if err := calls[2].Unpack(ownable, "owner", &owner); err != nil || owner != contracts[2] {
+			t.Fatalf("owner expected %s, got %s, err: %v", contracts[2].Hex(), owner.Hex(), err)
+		}
+	}
+
+	owners, err := sdk.GetOwnerships(context.Background(), testMulticallAddr, []common.Address{contracts[0], contracts[2]})
+	if err != nil || owners[0] != contracts[0] || owners[1] != contracts[2] {
+		t.Fatalf("unexpected owners %v, err: %v", owners, err)
+	}
+}
+
+func TestEthereumSdk_GetBindings(t *testing.T) {
+	sdk, _ := newBatchTestSdk(t)
+	proxy := common.HexToAddress("0x0e")
+
+	proxies, err := sdk.GetProxyHashMaps(context.Background(), testMulticallAddr, proxy, []uint64{2, 6})
+	if err != nil || len(proxies) != 2 || common.BytesToAddress(proxies[1]) != proxy {
+		t.Fatalf("unexpected proxy hashes %x, err: %v", proxies, err)
+	}
+	assets, err := sdk.GetAssetHashMaps(context.Background(), EmptyAddress, proxy,
+		[]common.Address{common.HexToAddress("0x0a")}, []uint64{2})
+	if err != nil || len(assets) != 1 || common.BytesToAddress(assets[0]) != proxy {
+		t.Fatalf("unexpected asset hashes %x, err: %v", assets, err)
+	}
+	if _, err := sdk.GetAssetHashMaps(context.Background(), EmptyAddress, proxy, nil, []uint64{2}); err == nil {
+		t.Fatalf("assets and chains mismatch should fail")
+	}
+}
+
+func TestEthereumSdk_GetERC20Metas(t *testing.T) {
+	sdk, _ := newBatchTestSdk(t)
+	tokens := []common.Address{common.HexToAddress("0x06"), NativeFeeToken, common.HexToAddress("0x12")}
+
+	metas, err := sdk.GetERC20Metas(context.Background(), testMulticallAddr, tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metas[0].Err != nil || metas[0].Symbol != "T06" || metas[0].Decimals != 6 || metas[0].TotalSupply.Int64() != 6 {
+		t.Fatalf("unexpected meta %+v", metas[0])
+	}
+	if metas[1].Symbol != "native" || metas[1].Decimals != NativeDecimals || metas[1].TotalSupply != nil {
+		t.Fatalf("unexpected native meta %+v", metas[1])
+	}
+	if metas[2].Err != nil || metas[2].Symbol != "T12" || metas[2].Decimals != 18 {
+		t.Fatalf("unexpected meta %+v", metas[2])
+	}
+}
+
+func TestEthereumSdk_GetLockProxyBalances(t *testing.T) {
+	sdk, _ := newBatchTestSdk(t)
+	proxy := common.HexToAddress("0x0e")
+
+	balances, err := sdk.GetLockProxyBalances(context.Background(), testMulticallAddr, proxy,
+		[]common.Address{common.HexToAddress("0x0a"), NativeFeeToken})
+	if err != nil || len(balances) != 2 || balances[1].Int64() != 0x0e {
+		t.Fatalf("unexpected balances %v, err: %v", balances, err)
+	}
+}
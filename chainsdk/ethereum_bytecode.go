@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Notice: functions in this file only used for deploy_tool and test cases.
+package chainsdk
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/core/vm/runtime"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// CodeStatus is the result of comparing code deployed with the runtime code of go_abi bindings.
+type CodeStatus int
+
+const (
+	CodeMatch CodeStatus = iota
+	CodeMetadataDiffers
+	CodeImmutablesDiffer
+	CodeMismatch
+	CodeNotDeployed
+)
+
+func (s CodeStatus) String() string {
+	switch s {
+	case CodeMatch:
+		return "match"
+	case CodeMetadataDiffers:
+		return "metadata differs"
+	case CodeImmutablesDiffer:
+		return "immutables differ"
+	case CodeMismatch:
+		return "mismatch"
+	case CodeNotDeployed:
+		return "not deployed"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+func (s CodeStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// RuntimeCode runs the constructor of contract in an empty evm and returns the code it deploys,
+// `bin` is one of *Bin in go_abi and `args` are the constructor args, so that immutables are
+// filled as the contract deployed with the same args.
+func RuntimeCode(abiStr, bin string, args ...interface{}) ([]byte, error) {
+	initCode, err := DeployData(abiStr, bin, args...)
+	if err != nil {
+		return nil, err
+	}
+	code, _, _, err := runtime.Create(initCode, &runtime.Config{ChainConfig: params.AllEthashProtocolChanges})
+	if err != nil {
+		return nil, fmt.Errorf("run constructor err: %v", err)
+	}
+	return code, nil
+}
+
+// SplitMetadata splits the cbor encoded metadata appended by solc from code, the length of metadata
+// is in the last 2 bytes. metadata is nil if code doesn't end with it.
+func SplitMetadata(code []byte) (body, metadata []byte) {
+	if len(code) < 2 {
+		return code, nil
+	}
+	size := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	start := len(code) - 2 - size
+	// metadata is a cbor map, major type 5
+	if size == 0 || start < 0 || code[start]&0xe0 != 0xa0 {
+		return code, nil
+	}
+	return code[:start], code[start:]
+}
+
+// pushOperands marks bytes of code which are operands of PUSH32, immutables are filled there.
+func pushOperands(code []byte) []bool {
+	marks := make([]bool, len(code))
+	for pc := 0; pc < len(code); pc++ {
+		op := vm.OpCode(code[pc])
+		if !op.IsPush() {
+			continue
+		}
+		size := int(op - vm.PUSH1 + 1)
+		if op == vm.PUSH32 {
+			for i := pc + 1; i <= pc+size && i < len(code); i++ {
+				marks[i] = true
+			}
+		}
+		pc += size
+	}
+	return marks
+}
+
+// CompareCode compares code deployed with the expected runtime code, the metadata hash is ignored
+// since it changes with source paths and comments. the code is regarded as built from the same source
+// with different constructor args if only operands of PUSH32 differ. detail explains the difference.
+func CompareCode(deployed, expected []byte) (status CodeStatus, detail string) {
+	if len(deployed) == 0 {
+		return CodeNotDeployed, "no code at address"
+	}
+	if bytes.Equal(deployed, expected) {
+		return CodeMatch, ""
+	}
+	deployedBody, _ := SplitMetadata(deployed)
+	expectedBody, _ := SplitMetadata(expected)
+	if bytes.Equal(deployedBody, expectedBody) {
+		return CodeMetadataDiffers, "only metadata hash differs"
+	}
+	if len(deployedBody) != len(expectedBody) {
+		return CodeMismatch, fmt.Sprintf("code size %d, expected %d", len(deployedBody), len(expectedBody))
+	}
+	marks := pushOperands(expectedBody)
+	diffs := 0
+	for i := range expectedBody {
+		if deployedBody[i] == expectedBody[i] {
+			continue
+		}
+		if !marks[i] {
+			return CodeMismatch, fmt.Sprintf("first difference at offset %d", i)
+		}
+		diffs++
+	}
+	return CodeImmutablesDiffer, fmt.Sprintf("%d bytes of push32 operands differ", diffs)
+}
@@ -0,0 +1,89 @@
+package chainsdk
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"poly-bridge/go_abi/eccd_abi"
+	"poly-bridge/go_abi/eccm_abi"
+)
+
+func TestRuntimeCode(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	auth := bind.NewKeyedTransactor(key)
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		auth.From: {Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)},
+	}, 10000000)
+	defer sim.Close()
+
+	eccd, _, _, err := eccd_abi.DeployEthCrossChainData(auth, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	eccm, _, _, err := eccm_abi.DeployEthCrossChainManager(auth, sim, eccd, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	expected, err := RuntimeCode(eccm_abi.EthCrossChainManagerABI, eccm_abi.EthCrossChainManagerBin, eccd, uint64(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	deployed, err := sim.CodeAt(context.Background(), eccm, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status, detail := CompareCode(deployed, expected); status != CodeMatch {
+		t.Fatalf("eccm code expected match, got %s: %s", status, detail)
+	}
+	if status, _ := CompareCode(deployed, nil); status != CodeMismatch {
+		t.Fatalf("different code expected mismatch, got %s", status)
+	}
+}
+
+func TestCompareCode(t *testing.T) {
+	metadata := append(common.FromHex("0xa165627a7a7231582000"), 0x00, 0x0a)
+	// PUSH1 0x7f PUSH32 0x0101...01 STOP
+	body := append([]byte{0x60, 0x7f, 0x7f}, bytes.Repeat([]byte{0x01}, 32)...)
+	body = append(body, 0x00)
+	expected := append(append([]byte{}, body...), metadata...)
+
+	if b, m := SplitMetadata(expected); !bytes.Equal(b, body) || !bytes.Equal(m, metadata) {
+		t.Fatalf("unexpected split %x %x", b, m)
+	}
+	if b, m := SplitMetadata(body); !bytes.Equal(b, body) || m != nil {
+		t.Fatalf("code without metadata should not be split")
+	}
+
+	tamper := func(code []byte, offset int) []byte {
+		code = append([]byte{}, code...)
+		code[offset] ^= 0xff
+		return code
+	}
+	cases := []struct {
+		deployed []byte
+		status   CodeStatus
+	}{
+		{expected, CodeMatch},
+		{tamper(expected, len(expected)-4), CodeMetadataDiffers},
+		{tamper(expected, 1), CodeMismatch},
+		{tamper(expected, 3), CodeImmutablesDiffer},
+		{tamper(expected, len(body)-1), CodeMismatch},
+		{body[:len(body)-1], CodeMismatch},
+		{nil, CodeNotDeployed},
+	}
+	for i, c := range cases {
+		if status, detail := CompareCode(c.deployed, expected); status != c.status {
+			t.Fatalf("case %d expected %s, got %s: %s", i, c.status, status, detail)
+		}
+	}
+}
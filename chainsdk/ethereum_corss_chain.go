@@ -20,14 +20,18 @@
 package chainsdk
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
+	"strings"
 	"time"
 
 	log "github.com/astaxie/beego/logs"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"poly-bridge/go_abi/eccd_abi"
 	"poly-bridge/go_abi/eccm_abi"
 	"poly-bridge/go_abi/eccmp_abi"
@@ -120,6 +124,220 @@ func (s *EthereumSdk) BindERC20Asset(
 	return tx.Hash(), nil
 }
 
+// SendBindERC20Asset sends BindAssetHash tx with `nonce` and returns without waiting it packed, it's
+// used to bind assets in bulk.
+func (s *EthereumSdk) SendBindERC20Asset(
+	key *ecdsa.PrivateKey,
+	lockProxyAddr,
+	fromAssetHash,
+	toAssetHash common.Address,
+	targetSideChainId uint64,
+	nonce uint64,
+) (common.Hash, error) {
+
+	proxy, err := erc20lp.NewLockProxy(lockProxyAddr, s.backend())
+	if err != nil {
+		return EmptyHash, err
+	}
+
+	auth, err := s.makeAuthWithNonce(key, nonce, DefaultGasLimit)
+	if err != nil {
+		return EmptyHash, err
+	}
+	tx, err := proxy.BindAssetHash(auth, fromAssetHash, targetSideChainId, toAssetHash[:])
+	if err != nil {
+		return EmptyHash, err
+	}
+	return tx.Hash(), nil
+}
+
+// LockAsset locks `amount` of asset in lock proxy toward `toAddress` on chain `toChainId`, native coin
+// is sent along with the tx if `fromAssetHash` is empty address.
+func (s *EthereumSdk) LockAsset(
+	key *ecdsa.PrivateKey,
+	lockProxyAddr,
+	fromAssetHash common.Address,
+	toChainId uint64,
+	toAddress []byte,
+	amount *big.Int,
+) (common.Hash, error) {
+
+	proxy, err := erc20lp.NewLockProxy(lockProxyAddr, s.backend())
+	if err != nil {
+		return EmptyHash, err
+	}
+
+	auth, err := s.makeAuth(key, DefaultGasLimit)
+	if err != nil {
+		return EmptyHash, err
+	}
+	if fromAssetHash == NativeFeeToken {
+		auth.Value = amount
+	}
+	tx, err := proxy.Lock(auth, fromAssetHash, toChainId, toAddress, amount)
+	if err != nil {
+		return EmptyHash, err
+	}
+	if err := s.waitTxConfirm(tx.Hash()); err != nil {
+		return EmptyHash, err
+	}
+	return tx.Hash(), nil
+}
+
+// GetAssetHashMap returns the asset on chain `toChainId` bound to `fromAssetHash` in lock proxy.
+func (s *EthereumSdk) GetAssetHashMap(lockProxyAddr, fromAssetHash common.Address, toChainId uint64) ([]byte, error) {
+	proxy, err := erc20lp.NewLockProxyCaller(lockProxyAddr, s.rawClient)
+	if err != nil {
+		return nil, err
+	}
+	return proxy.AssetHashMap(nil, fromAssetHash, toChainId)
+}
+
+func (s *EthereumSdk) BindProxyHash(
+	key *ecdsa.PrivateKey,
+	lockProxyAddr,
+	targetProxyHash common.Address,
+	toChainId uint64,
+) (common.Hash, error) {
+
+	proxy, err := erc20lp.NewLockProxy(lockProxyAddr, s.backend())
+	if err != nil {
+		return EmptyHash, err
+	}
+
+	auth, err := s.makeAuth(key, DefaultGasLimit)
+	if err != nil {
+		return EmptyHash, err
+	}
+	tx, err := proxy.BindProxyHash(auth, toChainId, targetProxyHash[:])
+	if err != nil {
+		return EmptyHash, err
+	}
+	if err := s.waitTxConfirm(tx.Hash()); err != nil {
+		return EmptyHash, err
+	}
+	return tx.Hash(), nil
+}
+
+// GetProxyHashMap returns the lock proxy on chain `toChainId` bound in lock proxy.
+func (s *EthereumSdk) GetProxyHashMap(lockProxyAddr common.Address, toChainId uint64) ([]byte, error) {
+	proxy, err := erc20lp.NewLockProxyCaller(lockProxyAddr, s.rawClient)
+	if err != nil {
+		return nil, err
+	}
+	return proxy.ProxyHashMap(nil, toChainId)
+}
+
+// GetProxyHashMaps reads the lock proxies bound to chains `toChainIds` in one round trip.
+func (s *EthereumSdk) GetProxyHashMaps(
+	ctx context.Context,
+	multicallAddr,
+	lockProxyAddr common.Address,
+	toChainIds []uint64,
+) ([][]byte, error) {
+
+	calls := make([]*BatchCall, 0, len(toChainIds))
+	for _, toChainId := range toChainIds {
+		call, err := NewBatchCall(lockProxyABI, lockProxyAddr, "proxyHashMap", toChainId)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	if err := s.AggregateCall(ctx, multicallAddr, calls); err != nil {
+		return nil, err
+	}
+	hashes := make([][]byte, len(calls))
+	for i, call := range calls {
+		if err := call.Unpack(lockProxyABI, "proxyHashMap", &hashes[i]); err != nil {
+			return nil, fmt.Errorf("get proxy hash of chain %d err: %v", toChainIds[i], err)
+		}
+	}
+	return hashes, nil
+}
+
+// GetAssetHashMaps reads the assets bound to `assets[i]` on chain `toChainIds[i]` in one round trip.
+func (s *EthereumSdk) GetAssetHashMaps(
+	ctx context.Context,
+	multicallAddr,
+	lockProxyAddr common.Address,
+	assets []common.Address,
+	toChainIds []uint64,
+) ([][]byte, error) {
+
+	if len(assets) != len(toChainIds) {
+		return nil, fmt.Errorf("%d assets mismatch %d chains", len(assets), len(toChainIds))
+	}
+	calls := make([]*BatchCall, 0, len(assets))
+	for i, asset := range assets {
+		call, err := NewBatchCall(lockProxyABI, lockProxyAddr, "assetHashMap", asset, toChainIds[i])
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	if err := s.AggregateCall(ctx, multicallAddr, calls); err != nil {
+		return nil, err
+	}
+	hashes := make([][]byte, len(calls))
+	for i, call := range calls {
+		if err := call.Unpack(lockProxyABI, "assetHashMap", &hashes[i]); err != nil {
+			return nil, fmt.Errorf("get asset hash of %s to chain %d err: %v", assets[i].Hex(), toChainIds[i], err)
+		}
+	}
+	return hashes, nil
+}
+
+// GetLockProxyBalances reads balances of assets held by lock proxy in one round trip, native coin is
+// read for empty address.
+func (s *EthereumSdk) GetLockProxyBalances(
+	ctx context.Context,
+	multicallAddr,
+	lockProxyAddr common.Address,
+	assets []common.Address,
+) ([]*big.Int, error) {
+
+	calls := make([]*BatchCall, 0, len(assets))
+	for _, asset := range assets {
+		call, err := NewBatchCall(lockProxyABI, lockProxyAddr, "getBalanceFor", asset)
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	if err := s.AggregateCall(ctx, multicallAddr, calls); err != nil {
+		return nil, err
+	}
+	balances := make([]*big.Int, len(calls))
+	for i, call := range calls {
+		if err := call.Unpack(lockProxyABI, "getBalanceFor", &balances[i]); err != nil {
+			return nil, fmt.Errorf("get balance of %s err: %v", assets[i].Hex(), err)
+		}
+	}
+	return balances, nil
+}
+
+// GetUnlockEvents returns UnlockEvent emitted by lock proxy in receipt.
+func GetUnlockEvents(lockProxyAddr common.Address, receipt *types.Receipt) ([]*erc20lp.LockProxyUnlockEvent, error) {
+	filterer, err := erc20lp.NewLockProxyFilterer(lockProxyAddr, nil)
+	if err != nil {
+		return nil, err
+	}
+	topic := lockProxyABI.Events["UnlockEvent"].ID
+	events := make([]*erc20lp.LockProxyUnlockEvent, 0)
+	for _, log := range receipt.Logs {
+		if log.Address != lockProxyAddr || len(log.Topics) == 0 || log.Topics[0] != topic {
+			continue
+		}
+		evt, err := filterer.ParseUnlockEvent(*log)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, evt)
+	}
+	return events, nil
+}
+
 func (s *EthereumSdk) TransferECCDOwnership(key *ecdsa.PrivateKey, eccd, eccm common.Address) (common.Hash, error) {
 
 	eccdContract, err := eccd_abi.NewEthCrossChainData(eccd, s.backend())
@@ -209,6 +427,37 @@ func (s *EthereumSdk) GetCCMPOwnership(ccmpAddr common.Address) (common.Address,
 	return ccmp.Owner(nil)
 }
 
+// GetOwnerships reads owners of the Ownable contracts in one round trip.
+func (s *EthereumSdk) GetOwnerships(
+	ctx context.Context,
+	multicallAddr common.Address,
+	contracts []common.Address,
+) ([]common.Address, error) {
+
+	ownable, err := abi.JSON(strings.NewReader(eccd_abi.OwnableABI))
+	if err != nil {
+		return nil, err
+	}
+	calls := make([]*BatchCall, 0, len(contracts))
+	for _, contract := range contracts {
+		call, err := NewBatchCall(ownable, contract, "owner")
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, call)
+	}
+	if err := s.AggregateCall(ctx, multicallAddr, calls); err != nil {
+		return nil, err
+	}
+	owners := make([]common.Address, len(calls))
+	for i, call := range calls {
+		if err := call.Unpack(ownable, "owner", &owners[i]); err != nil {
+			return nil, fmt.Errorf("get owner of %s err: %v", contracts[i].Hex(), err)
+		}
+	}
+	return owners, nil
+}
+
 func (s *EthereumSdk) InitGenesisBlock(key *ecdsa.PrivateKey, eccmAddr common.Address, rawHdr, publickeys []byte) (common.Hash, error) {
 	eccm, err := eccm_abi.NewEthCrossChainManager(eccmAddr, s.backend())
 	if err != nil {
@@ -231,14 +480,16 @@ func (s *EthereumSdk) InitGenesisBlock(key *ecdsa.PrivateKey, eccmAddr common.Ad
 }
 
 func (s *EthereumSdk) dumpTx(hash common.Hash) error {
-	tx, err := s.GetTransactionReceipt(hash)
+	tx, err := s.GetTransactionReceipt(context.Background(), hash)
 	if err != nil {
 		return fmt.Errorf("faild to get receipt %s", hash.Hex())
 	}
 
 	if tx.Status == 0 {
+		ethTxReverted.With(s.url).Inc()
 		return fmt.Errorf("receipt failed %s", hash.Hex())
 	}
+	ethTxConfirmed.With(s.url).Inc()
 
 	log.Info("txhash %s, block height %d", hash.Hex(), tx.BlockNumber.Uint64())
 	for _, event := range tx.Logs {
@@ -253,12 +504,17 @@ func (s *EthereumSdk) dumpTx(hash common.Hash) error {
 
 func (s *EthereumSdk) makeAuth(key *ecdsa.PrivateKey, gasLimit uint64) (*bind.TransactOpts, error) {
 	authAddress := xecdsa.Key2address(key)
-	nonce, err := s.NonceAt(authAddress)
+	nonce, err := s.NonceAt(context.Background(), authAddress)
 	if err != nil {
 		return nil, fmt.Errorf("makeAuth, addr %s, err %v", authAddress.Hex(), err)
 	}
+	return s.makeAuthWithNonce(key, nonce, gasLimit)
+}
 
-	gasPrice, err := s.SuggestGasPrice()
+// makeAuthWithNonce is used when nonces of sequential txs are managed by caller.
+func (s *EthereumSdk) makeAuthWithNonce(key *ecdsa.PrivateKey, nonce, gasLimit uint64) (*bind.TransactOpts, error) {
+	authAddress := xecdsa.Key2address(key)
+	gasPrice, err := s.SuggestGasPrice(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("makeAuth, get suggest gas price err: %v", err)
 	}
@@ -280,14 +536,17 @@ func (s *EthereumSdk) makeAuth(key *ecdsa.PrivateKey, gasLimit uint64) (*bind.Tr
 
 func (s *EthereumSdk) waitTxConfirm(hash common.Hash) error {
 	ticker := time.NewTicker(time.Second * 1)
-	end := time.Now().Add(30 * time.Second)
+	defer ticker.Stop()
+	start := time.Now()
+	end := start.Add(30 * time.Second)
 	for now := range ticker.C {
-		_, pending, err := s.TransactionByHash(hash)
+		_, pending, err := s.TransactionByHash(context.Background(), hash)
 		if err != nil {
 			log.Debug("failed to call TransactionByHash: %v", err)
 			continue
 		}
 		if !pending {
+			ethTxConfirmDuration.With(s.url).Observe(time.Since(start).Seconds())
 			break
 		}
 		if now.Before(end) {
@@ -305,5 +564,19 @@ func (s *EthereumSdk) waitTxConfirm(hash common.Hash) error {
 }
 
 func (s *EthereumSdk) backend() bind.ContractBackend {
-	return s.rawClient
+	return &meteredBackend{ContractBackend: s.rawClient, url: s.url}
+}
+
+// meteredBackend counts transactions sent by contract bindings.
+type meteredBackend struct {
+	bind.ContractBackend
+	url string
+}
+
+func (b *meteredBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.ContractBackend.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	ethTxSent.With(b.url).Inc()
+	return nil
 }
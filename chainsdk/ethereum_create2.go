@@ -0,0 +1,186 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Notice: functions in this file only used for deploy_tool and test cases.
+
+package chainsdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	xecdsa "poly-bridge/utils/ecdsa"
+)
+
+// deterministicDeployerTx deploys the deterministic deployment proxy, which creates contract of
+// calldata `salt ++ init code` with CREATE2, see github.com/Arachnid/deterministic-deployment-proxy.
+// the tx is presigned without chain id, so the proxy is at the same address on all evm chains.
+const deterministicDeployerTx = "0xf8a58085174876e800830186a08080b853604580600e600039806000f350fe7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe03601600081602082378035828234f58015156039578182fd5b8082525050506014600cf31ba02222222222222222222222222222222222222222222222222222222222222222a02222222222222222222222222222222222222222222222222222222222222222"
+
+// create2FactoryCode is the creation code of factory which deploys contract of calldata
+// `salt ++ init code` with CREATE2 and transfers ownership of the contract to caller, the address is
+// returned in 32 bytes. the salt of CREATE2 is keccak256(caller ++ salt), so nobody else can take the
+// address and own the contract. the runtime code is:
+//
+//	CALLER PUSH1 0 MSTORE PUSH1 0 CALLDATALOAD PUSH1 0x20 MSTORE PUSH1 0x40 PUSH1 0 SHA3
+//	PUSH1 0x20 CALLDATASIZE SUB DUP1 PUSH1 0x20 PUSH1 0 CALLDATACOPY
+//	PUSH1 0 CALLVALUE CREATE2 DUP1 ISZERO PUSH1 fail JUMPI
+//	PUSH4 transferOwnership(address) PUSH1 0xe0 SHL PUSH1 0 MSTORE CALLER PUSH1 4 MSTORE
+//	PUSH1 0 PUSH1 0 PUSH1 0x24 PUSH1 0 PUSH1 0 DUP6 GAS CALL ISZERO PUSH1 fail JUMPI
+//	PUSH1 0 MSTORE PUSH1 0x20 PUSH1 0 RETURN
+//	fail: JUMPDEST RETURNDATASIZE PUSH1 0 DUP1 RETURNDATACOPY RETURNDATASIZE PUSH1 0 REVERT
+const create2FactoryCode = "0x605480600b6000396000f333600052600035602052604060002060203603806020600037600034f58015604a5763f2fde38b60e01b6000523360045260006000602460006000855af115604a5760005260206000f35b3d6000803e3d6000fd"
+
+var (
+	DeterministicDeployer       = common.HexToAddress("0x4e59b44847b379578588920ca78fbf26c0b4956c")
+	DeterministicDeployerSigner = common.HexToAddress("0x3fab184622dc19b6109349b94811493bf2a45362")
+	// DeterministicDeployerCost is the gas fee of deterministicDeployerTx, 100000 gas at 100 gwei.
+	DeterministicDeployerCost = new(big.Int).Mul(big.NewInt(100000), big.NewInt(100000000000))
+
+	// Create2Factory is deployed through DeterministicDeployer with zero salt.
+	Create2Factory = crypto.CreateAddress2(DeterministicDeployer, [32]byte{},
+		crypto.Keccak256(common.FromHex(create2FactoryCode)))
+)
+
+// Create2Salt returns `salt` if it's 32 bytes hex, otherwise keccak256 of it.
+func Create2Salt(salt string) common.Hash {
+	if raw, err := hex.DecodeString(strings.TrimPrefix(salt, "0x")); err == nil && len(raw) == 32 {
+		return common.BytesToHash(raw)
+	}
+	return crypto.Keccak256Hash([]byte(salt))
+}
+
+// Create2Address returns the address of contract deployed by `sender` through Create2Factory.
+func Create2Address(sender common.Address, salt common.Hash, initCode []byte) common.Address {
+	realSalt := crypto.Keccak256Hash(common.LeftPadBytes(sender.Bytes(), 32), salt[:])
+	return crypto.CreateAddress2(Create2Factory, realSalt, crypto.Keccak256(initCode))
+}
+
+// sendCall sends tx with `data` to contract, gas limit is estimated and 20% added.
+func (s *EthereumSdk) sendCall(key *ecdsa.PrivateKey, to common.Address, data []byte) (common.Hash, error) {
+	from := xecdsa.Key2address(key)
+	nonce, err := s.NonceAt(context.Background(), from)
+	if err != nil {
+		return EmptyHash, err
+	}
+	gasPrice, err := s.SuggestGasPrice(context.Background())
+	if err != nil {
+		return EmptyHash, err
+	}
+	gasLimit, err := s.EstimateGas(context.Background(), ethereum.CallMsg{
+		From: from, To: &to, GasPrice: gasPrice, Data: data,
+	})
+	if err != nil {
+		return EmptyHash, fmt.Errorf("estimate gas err: %v", err)
+	}
+
+	tx := types.NewTransaction(nonce, to, big.NewInt(0), gasLimit*6/5, gasPrice, data)
+	signedTx, err := types.SignTx(tx, types.HomesteadSigner{}, key)
+	if err != nil {
+		return EmptyHash, err
+	}
+	if err := s.SendRawTransaction(context.Background(), signedTx); err != nil {
+		return EmptyHash, err
+	}
+	if err := s.waitTxConfirm(signedTx.Hash()); err != nil {
+		return EmptyHash, err
+	}
+	return signedTx.Hash(), nil
+}
+
+// hasCode returns true if contract is deployed at addr.
+func (s *EthereumSdk) hasCode(addr common.Address) (bool, error) {
+	code, err := s.CodeAt(context.Background(), addr)
+	return len(code) > 0, err
+}
+
+// EnsureCreate2Factory deploys DeterministicDeployer and Create2Factory if they're missing, the
+// signer of DeterministicDeployer is funded by `key` if needed.
+func (s *EthereumSdk) EnsureCreate2Factory(key *ecdsa.PrivateKey) error {
+	if ok, err := s.hasCode(Create2Factory); err != nil || ok {
+		return err
+	}
+
+	ok, err := s.hasCode(DeterministicDeployer)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		balance, err := s.GetNativeBalance(DeterministicDeployerSigner)
+		if err != nil {
+			return err
+		}
+		if balance.Cmp(DeterministicDeployerCost) < 0 {
+			lack := new(big.Int).Sub(DeterministicDeployerCost, balance)
+			if _, err := s.TransferNative(key, DeterministicDeployerSigner, lack); err != nil {
+				return fmt.Errorf("fund deterministic deployer signer err: %v", err)
+			}
+		}
+		tx := new(types.Transaction)
+		if err := rlp.DecodeBytes(common.FromHex(deterministicDeployerTx), tx); err != nil {
+			return err
+		}
+		if err := s.SendRawTransaction(context.Background(), tx); err != nil {
+			return fmt.Errorf("send deterministic deployer tx err: %v, the node may reject tx without chain id", err)
+		}
+		if err := s.waitTxConfirm(tx.Hash()); err != nil {
+			return err
+		}
+		if ok, err := s.hasCode(DeterministicDeployer); err != nil || !ok {
+			return fmt.Errorf("deterministic deployer is not deployed by tx %s, err: %v", tx.Hash().Hex(), err)
+		}
+	}
+
+	data := append(make([]byte, 32), common.FromHex(create2FactoryCode)...)
+	hash, err := s.sendCall(key, DeterministicDeployer, data)
+	if err != nil {
+		return fmt.Errorf("deploy create2 factory err: %v", err)
+	}
+	if ok, err := s.hasCode(Create2Factory); err != nil || !ok {
+		return fmt.Errorf("create2 factory is not deployed by tx %s, err: %v", hash.Hex(), err)
+	}
+	return nil
+}
+
+// DeployCreate2 deploys contract of `initCode` through Create2Factory and the contract is owned by
+// `key`. it returns false without sending tx if the contract is deployed already.
+func (s *EthereumSdk) DeployCreate2(key *ecdsa.PrivateKey, salt common.Hash, initCode []byte) (common.Address, bool, error) {
+	addr := Create2Address(xecdsa.Key2address(key), salt, initCode)
+	if ok, err := s.hasCode(addr); err != nil || ok {
+		return addr, false, err
+	}
+	if err := s.EnsureCreate2Factory(key); err != nil {
+		return addr, false, err
+	}
+	hash, err := s.sendCall(key, Create2Factory, append(salt[:], initCode...))
+	if err != nil {
+		return addr, false, err
+	}
+	if ok, err := s.hasCode(addr); err != nil || !ok {
+		return addr, false, fmt.Errorf("contract is not deployed at %s by tx %s, err: %v", addr.Hex(), hash.Hex(), err)
+	}
+	return addr, true, nil
+}
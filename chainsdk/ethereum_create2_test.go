@@ -0,0 +1,97 @@
+package chainsdk
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"poly-bridge/go_abi/eccd_abi"
+)
+
+func TestCreate2Salt(t *testing.T) {
+	raw := "0x000000000000000000000000000000000000000000000000000000000000002a"
+	if Create2Salt(raw) != common.HexToHash(raw) {
+		t.Fatalf("32 bytes hex salt should be used directly")
+	}
+	if Create2Salt("eccd") != crypto.Keccak256Hash([]byte("eccd")) {
+		t.Fatalf("other salt should be hashed")
+	}
+}
+
+func TestCreate2Factory(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	ether := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		from:                        {Balance: ether},
+		DeterministicDeployerSigner: {Balance: DeterministicDeployerCost},
+	}, 10000000)
+	defer sim.Close()
+	ctx := context.Background()
+
+	nonce := uint64(0)
+	send := func(to common.Address, data []byte) *types.Receipt {
+		tx := types.NewTransaction(nonce, to, big.NewInt(0), 5000000, big.NewInt(1), data)
+		nonce++
+		signedTx, err := types.SignTx(tx, types.HomesteadSigner{}, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sim.SendTransaction(ctx, signedTx); err != nil {
+			t.Fatal(err)
+		}
+		sim.Commit()
+		receipt, err := sim.TransactionReceipt(ctx, signedTx.Hash())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return receipt
+	}
+
+	deployerTx := new(types.Transaction)
+	if err := rlp.DecodeBytes(common.FromHex(deterministicDeployerTx), deployerTx); err != nil {
+		t.Fatal(err)
+	}
+	if err := sim.SendTransaction(ctx, deployerTx); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+	if code, _ := sim.CodeAt(ctx, DeterministicDeployer, nil); len(code) == 0 {
+		t.Fatalf("deterministic deployer is not deployed")
+	}
+
+	if receipt := send(DeterministicDeployer, append(make([]byte, 32), common.FromHex(create2FactoryCode)...)); receipt.Status != 1 {
+		t.Fatalf("deploy create2 factory failed")
+	}
+	if code, _ := sim.CodeAt(ctx, Create2Factory, nil); len(code) == 0 {
+		t.Fatalf("create2 factory is not deployed at %s", Create2Factory.Hex())
+	}
+
+	initCode, err := DeployData(eccd_abi.EthCrossChainDataABI, eccd_abi.EthCrossChainDataBin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	salt := Create2Salt("eccd")
+	if receipt := send(Create2Factory, append(salt[:], initCode...)); receipt.Status != 1 {
+		t.Fatalf("deploy eccd through create2 factory failed")
+	}
+	addr := Create2Address(from, salt, initCode)
+	eccd, err := eccd_abi.NewEthCrossChainData(addr, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owner, err := eccd.Owner(&bind.CallOpts{}); err != nil || owner != from {
+		t.Fatalf("owner of eccd at %s expected %s, got %s, err: %v", addr.Hex(), from.Hex(), owner.Hex(), err)
+	}
+
+	if receipt := send(Create2Factory, append(salt[:], initCode...)); receipt.Status != 0 {
+		t.Fatalf("deploy to the same address again should fail")
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Notice: functions in this file only used for deploy_tool and test cases.
+
+package chainsdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	erc20lp "poly-bridge/go_abi/lock_proxy_abi"
+)
+
+// erc20MetaABI is the abi of optional metadata methods of erc20, which are not in IERC20.
+const erc20MetaABI = `[{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},{"constant":true,"inputs":[],"name":"totalSupply","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`
+
+// NativeDecimals is the decimals of native coin of ethereum like chains.
+const NativeDecimals = 18
+
+var erc20Meta abi.ABI
+
+func init() {
+	var err error
+	if erc20Meta, err = abi.JSON(strings.NewReader(erc20MetaABI)); err != nil {
+		panic(err)
+	}
+}
+
+// ERC20Meta is the metadata of erc20 token, Err is set if the token doesn't implement symbol or decimals.
+type ERC20Meta struct {
+	Token       common.Address
+	Symbol      string
+	Decimals    uint8
+	TotalSupply *big.Int
+	Err         error
+}
+
+func (s *EthereumSdk) ApproveERC20(
+	key *ecdsa.PrivateKey,
+	token,
+	spender common.Address,
+	amount *big.Int,
+) (common.Hash, error) {
+
+	erc20, err := erc20lp.NewIERC20Transactor(token, s.backend())
+	if err != nil {
+		return EmptyHash, err
+	}
+
+	auth, err := s.makeAuth(key, DefaultGasLimit)
+	if err != nil {
+		return EmptyHash, err
+	}
+	tx, err := erc20.Approve(auth, spender, amount)
+	if err != nil {
+		return EmptyHash, err
+	}
+	if err := s.waitTxConfirm(tx.Hash()); err != nil {
+		return EmptyHash, err
+	}
+	return tx.Hash(), nil
+}
+
+func (s *EthereumSdk) TransferERC20(
+	key *ecdsa.PrivateKey,
+	token,
+	to common.Address,
+	amount *big.Int,
+) (common.Hash, error) {
+
+	erc20, err := erc20lp.NewIERC20Transactor(token, s.backend())
+	if err != nil {
+		return EmptyHash, err
+	}
+
+	auth, err := s.makeAuth(key, DefaultGasLimit)
+	if err != nil {
+		return EmptyHash, err
+	}
+	tx, err := erc20.Transfer(auth, to, amount)
+	if err != nil {
+		return EmptyHash, err
+	}
+	if err := s.waitTxConfirm(tx.Hash()); err != nil {
+		return EmptyHash, err
+	}
+	return tx.Hash(), nil
+}
+
+func (s *EthereumSdk) GetERC20Allowance(ctx context.Context, token, owner, spender common.Address) (*big.Int, error) {
+	erc20, err := erc20lp.NewIERC20Caller(token, s.rawClient)
+	if err != nil {
+		return nil, err
+	}
+	return erc20.Allowance(&bind.CallOpts{Context: ctx}, owner, spender)
+}
+
+func (s *EthereumSdk) GetERC20Balance(ctx context.Context, token, owner common.Address) (*big.Int, error) {
+	erc20, err := erc20lp.NewIERC20Caller(token, s.rawClient)
+	if err != nil {
+		return nil, err
+	}
+	return erc20.BalanceOf(&bind.CallOpts{Context: ctx}, owner)
+}
+
+// GetAssetBalance returns native balance if asset is empty address, otherwise erc20 balance.
+func (s *EthereumSdk) GetAssetBalance(asset, owner common.Address) (*big.Int, error) {
+	if asset == NativeFeeToken {
+		return s.GetNativeBalance(owner)
+	}
+	return s.GetERC20Balance(context.Background(), asset, owner)
+}
+
+// GetERC20Metas reads symbol, decimals and total supply of tokens in one round trip, native coin is
+// returned for empty address with symbol `native` and nil total supply.
+func (s *EthereumSdk) GetERC20Metas(ctx context.Context, multicallAddr common.Address, tokens []common.Address) ([]*ERC20Meta, error) {
+	metas := make([]*ERC20Meta, len(tokens))
+	calls := make([]*BatchCall, 0, 3*len(tokens))
+	for i, token := range tokens {
+		metas[i] = &ERC20Meta{Token: token}
+		if token == NativeFeeToken {
+			metas[i].Symbol, metas[i].Decimals = "native", NativeDecimals
+			continue
+		}
+		for _, method := range []string{"symbol", "decimals", "totalSupply"} {
+			call, err := NewBatchCall(erc20Meta, token, method)
+			if err != nil {
+				return nil, err
+			}
+			calls = append(calls, call)
+		}
+	}
+	if err := s.AggregateCall(ctx, multicallAddr, calls); err != nil {
+		return nil, err
+	}
+	for _, meta := range metas {
+		if meta.Token == NativeFeeToken {
+			continue
+		}
+		symbol, decimals, totalSupply := calls[0], calls[1], calls[2]
+		calls = calls[3:]
+		if err := symbol.Unpack(erc20Meta, "symbol", &meta.Symbol); err != nil {
+			meta.Err = fmt.Errorf("get symbol of %s err: %v", meta.Token.Hex(), err)
+		} else if err := decimals.Unpack(erc20Meta, "decimals", &meta.Decimals); err != nil {
+			meta.Err = fmt.Errorf("get decimals of %s err: %v", meta.Token.Hex(), err)
+		} else if err := totalSupply.Unpack(erc20Meta, "totalSupply", &meta.TotalSupply); err != nil {
+			meta.Err = fmt.Errorf("get total supply of %s err: %v", meta.Token.Hex(), err)
+		}
+	}
+	return metas, nil
+}
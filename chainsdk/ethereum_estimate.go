@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Notice: functions in this file only used for deploy_tool and test cases.
+
+package chainsdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// DeployData returns the creation code of contract, `bin` is one of *Bin in go_abi and `args` are
+// the constructor args.
+func DeployData(abiStr, bin string, args ...interface{}) ([]byte, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiStr))
+	if err != nil {
+		return nil, err
+	}
+	input, err := parsed.Pack("", args...)
+	if err != nil {
+		return nil, fmt.Errorf("pack constructor err: %v", err)
+	}
+	return append(common.FromHex(bin), input...), nil
+}
+
+// EstimateDeployGas estimates gas of contract creation sent by `from`.
+func (s *EthereumSdk) EstimateDeployGas(
+	ctx context.Context,
+	from common.Address,
+	abiStr, bin string,
+	args ...interface{},
+) (uint64, error) {
+
+	data, err := DeployData(abiStr, bin, args...)
+	if err != nil {
+		return 0, err
+	}
+	return s.EstimateGas(ctx, ethereum.CallMsg{From: from, Data: data})
+}
+
+// EstimateCallGas estimates gas of contract method call sent by `from`, the contract must be deployed.
+func (s *EthereumSdk) EstimateCallGas(
+	ctx context.Context,
+	from, contract common.Address,
+	abiStr, method string,
+	args ...interface{},
+) (uint64, error) {
+
+	parsed, err := abi.JSON(strings.NewReader(abiStr))
+	if err != nil {
+		return 0, err
+	}
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		return 0, fmt.Errorf("pack %s err: %v", method, err)
+	}
+	return s.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &contract, Data: data})
+}
@@ -41,17 +41,17 @@ func (s *EthereumSdk) TransferNative(
 ) (common.Hash, error) {
 
 	from := xecdsa.Key2address(key)
-	nonce, err := s.NonceAt(from)
+	nonce, err := s.NonceAt(context.Background(), from)
 	if err != nil {
 		return EmptyHash, err
 	}
 
-	gasPrice, err := s.SuggestGasPrice()
+	gasPrice, err := s.SuggestGasPrice(context.Background())
 	if err != nil {
 		return EmptyHash, err
 	}
 
-	gasLimit, err := s.EstimateGas(ethereum.CallMsg{
+	gasLimit, err := s.EstimateGas(context.Background(), ethereum.CallMsg{
 		From: from, To: &to, Gas: 0, GasPrice: gasPrice,
 		Value: amount, Data: []byte{},
 	})
@@ -64,7 +64,7 @@ func (s *EthereumSdk) TransferNative(
 	if err != nil {
 		return EmptyHash, err
 	}
-	if err := s.SendRawTransaction(signedTx); err != nil {
+	if err := s.SendRawTransaction(context.Background(), signedTx); err != nil {
 		return EmptyHash, err
 	}
 
@@ -19,6 +19,7 @@ package chainsdk
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
@@ -36,15 +37,16 @@ type EthereumSdk struct {
 	url       string
 }
 
+// NewEthereumSdk dials the node at url, http, ws and ipc endpoints are supported. The rpc client is
+// shared by json rpc calls and ethclient.
 func NewEthereumSdk(url string) (*EthereumSdk, error) {
-	rpcClient, err1 := rpc.Dial(url)
-	rawClient, err2 := ethclient.Dial(url)
-	if rpcClient == nil || err1 != nil || rawClient == nil || err2 != nil {
-		return nil, fmt.Errorf("ethereum node is not working!, err1: %v, err2: %v", err1, err2)
+	rpcClient, err := rpc.Dial(url)
+	if rpcClient == nil || err != nil {
+		return nil, fmt.Errorf("ethereum node is not working!, err: %v", err)
 	}
 	return &EthereumSdk{
 		rpcClient: rpcClient,
-		rawClient: rawClient,
+		rawClient: ethclient.NewClient(rpcClient),
 		url:       url,
 	}, nil
 }
@@ -53,96 +55,157 @@ func (ec *EthereumSdk) GetClient() *ethclient.Client {
 	return ec.rawClient
 }
 
-func (ec *EthereumSdk) GetCurrentBlockHeight() (uint64, error) {
+// Close closes the underlying rpc connection, the sdk can not be used any more.
+func (ec *EthereumSdk) Close() {
+	ec.rpcClient.Close()
+}
+
+func (ec *EthereumSdk) GetCurrentBlockHeight(ctx context.Context) (uint64, error) {
 	var result hexutil.Big
-	err := ec.rpcClient.CallContext(context.Background(), &result, "eth_blockNumber")
+	err := ec.rpcClient.CallContext(ctx, &result, "eth_blockNumber")
 	for err != nil {
 		return 0, err
 	}
 	return (*big.Int)(&result).Uint64(), err
 }
 
-func toBlockNumArg(number *big.Int) string {
-	if number == nil {
-		return "latest"
+// latestBlock is the block argument of json rpc which refers to the latest block.
+const latestBlock = "latest"
+
+// GetHeaderByNumber returns the given header
+func (ec *EthereumSdk) GetHeaderByNumber(ctx context.Context, number uint64) (*types.Header, error) {
+	raw, err := ec.getRawHeader(ctx, hexutil.EncodeUint64(number))
+	if err != nil {
+		return nil, err
 	}
-	return hexutil.EncodeBig(number)
+	return raw.Header, nil
 }
 
-// GetHeaderByNumber returns the given header
-func (ec *EthereumSdk) GetHeaderByNumber(number uint64) (*types.Header, error) {
-	var header *types.Header
-	var newNumber *big.Int
-	if number < 0 {
-		newNumber = nil
-	} else {
-		newNumber = big.NewInt(int64(number))
-	}
-	err := ec.rpcClient.CallContext(context.Background(), &header, "eth_getBlockByNumber", toBlockNumArg(newNumber), false)
-	for err != nil {
+// GetLatestHeader returns the header of the latest block.
+func (ec *EthereumSdk) GetLatestHeader(ctx context.Context) (*types.Header, error) {
+	raw, err := ec.getRawHeader(ctx, latestBlock)
+	if err != nil {
 		return nil, err
 	}
-	return header, err
+	return raw.Header, nil
+}
+
+// RawHeader is a block header as returned by the node. Raw keeps the original json, side chains may
+// return extra fields or hash the header differently, which would be lost by decoding into Header.
+type RawHeader struct {
+	Raw    json.RawMessage
+	Header *types.Header
+	Hash   common.Hash
+}
+
+// Verify recomputes the header hash and compares it with the hash reported by the node, it fails
+// when the chain hashes its header differently from ethereum.
+func (h *RawHeader) Verify() error {
+	if hash := h.Header.Hash(); hash != h.Hash {
+		return fmt.Errorf("header %d hash mismatch, node: %s, computed: %s", h.Header.Number, h.Hash.Hex(), hash.Hex())
+	}
+	return nil
 }
 
-func (ec *EthereumSdk) GetBlockByNumber(number uint64) (*types.Block, error) {
-	return ec.rawClient.BlockByNumber(context.Background(), new(big.Int).SetUint64(number))
+// GetRawHeaderByNumber returns the given header along with the original json of the node.
+func (ec *EthereumSdk) GetRawHeaderByNumber(ctx context.Context, number uint64) (*RawHeader, error) {
+	return ec.getRawHeader(ctx, hexutil.EncodeUint64(number))
 }
 
-func (ec *EthereumSdk) GetTransactionByHash(hash common.Hash) (*types.Transaction, error) {
-	tx, _, err := ec.rawClient.TransactionByHash(context.Background(), hash)
+// GetLatestRawHeader returns the latest header along with the original json of the node.
+func (ec *EthereumSdk) GetLatestRawHeader(ctx context.Context) (*RawHeader, error) {
+	return ec.getRawHeader(ctx, latestBlock)
+}
+
+func (ec *EthereumSdk) getRawHeader(ctx context.Context, block string) (*RawHeader, error) {
+	var raw json.RawMessage
+	err := ec.rpcClient.CallContext(ctx, &raw, "eth_getBlockByNumber", block, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, ethereum.NotFound
+	}
+	header := new(types.Header)
+	if err := json.Unmarshal(raw, header); err != nil {
+		return nil, fmt.Errorf("decode header %s: %v", block, err)
+	}
+	var fields struct {
+		Hash *common.Hash `json:"hash"`
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("decode header %s: %v", block, err)
+	}
+	if fields.Hash == nil {
+		return nil, fmt.Errorf("header %s has no hash field", block)
+	}
+	return &RawHeader{Raw: raw, Header: header, Hash: *fields.Hash}, nil
+}
+
+func (ec *EthereumSdk) GetBlockByNumber(ctx context.Context, number uint64) (*types.Block, error) {
+	return ec.rawClient.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+}
+
+func (ec *EthereumSdk) GetTransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, error) {
+	tx, _, err := ec.rawClient.TransactionByHash(ctx, hash)
 	for err != nil {
 		return nil, err
 	}
 	return tx, err
 }
 
-func (ec *EthereumSdk) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
-	receipt, err := ec.rawClient.TransactionReceipt(context.Background(), hash)
+func (ec *EthereumSdk) GetTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	receipt, err := ec.rawClient.TransactionReceipt(ctx, hash)
 	for err != nil {
 		return nil, err
 	}
 	return receipt, nil
 }
 
-func (ec *EthereumSdk) NonceAt(addr common.Address) (uint64, error) {
-	nonce, err := ec.rawClient.PendingNonceAt(context.Background(), addr)
+func (ec *EthereumSdk) NonceAt(ctx context.Context, addr common.Address) (uint64, error) {
+	nonce, err := ec.rawClient.PendingNonceAt(ctx, addr)
 	for err != nil {
 		return 0, err
 	}
 	return nonce, nil
 }
 
-func (ec *EthereumSdk) SendRawTransaction(tx *types.Transaction) error {
+func (ec *EthereumSdk) SendRawTransaction(ctx context.Context, tx *types.Transaction) error {
 	data, err := rlp.EncodeToBytes(tx)
 	if err != nil {
 		return err
 	}
-	err = ec.rpcClient.CallContext(context.Background(), nil, "eth_sendRawTransaction", hexutil.Encode(data))
+	err = ec.rpcClient.CallContext(ctx, nil, "eth_sendRawTransaction", hexutil.Encode(data))
 	for err != nil {
 		return err
 	}
+	ethTxSent.With(ec.url).Inc()
 	return nil
 }
 
-func (ec *EthereumSdk) TransactionByHash(hash common.Hash) (*types.Transaction, bool, error) {
-	tx, isPending, err := ec.rawClient.TransactionByHash(context.Background(), hash)
+func (ec *EthereumSdk) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	tx, isPending, err := ec.rawClient.TransactionByHash(ctx, hash)
 	for err != nil {
 		return nil, false, err
 	}
 	return tx, isPending, err
 }
 
-func (ec *EthereumSdk) SuggestGasPrice() (*big.Int, error) {
-	gasPrice, err := ec.rawClient.SuggestGasPrice(context.Background())
+func (ec *EthereumSdk) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	gasPrice, err := ec.rawClient.SuggestGasPrice(ctx)
 	for err != nil {
 		return nil, err
 	}
 	return gasPrice, err
 }
 
-func (ec *EthereumSdk) EstimateGas(msg ethereum.CallMsg) (uint64, error) {
-	gasLimit, err := ec.rawClient.EstimateGas(context.Background(), msg)
+// CodeAt returns the runtime code of contract at latest block, it's empty if no contract deployed.
+func (ec *EthereumSdk) CodeAt(ctx context.Context, contract common.Address) ([]byte, error) {
+	return ec.rawClient.CodeAt(ctx, contract, nil)
+}
+
+func (ec *EthereumSdk) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	gasLimit, err := ec.rawClient.EstimateGas(ctx, msg)
 	for err != nil {
 		return 0, err
 	}
@@ -18,288 +18,161 @@
 package chainsdk
 
 import (
-	"fmt"
-	"math"
+	"context"
 	"math/big"
-	"runtime/debug"
-	"sync"
 	"time"
 
-	"github.com/astaxie/beego/logs"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
-type EthereumInfo struct {
-	sdk          *EthereumSdk
-	latestHeight uint64
+type ethereumNode struct {
+	sdk *EthereumSdk
 }
 
-func NewEthereumInfo(url string) *EthereumInfo {
-	sdk, err := NewEthereumSdk(url)
-	if err != nil || sdk == nil {
-		panic(err)
-	}
-	return &EthereumInfo{
-		sdk:          sdk,
-		latestHeight: 0,
-	}
+func (n *ethereumNode) Height(ctx context.Context) (uint64, error) {
+	return n.sdk.GetCurrentBlockHeight(ctx)
 }
 
-type EthereumSdkPro struct {
-	infos         map[string]*EthereumInfo
-	selectionSlot uint64
-	id            uint64
-	mutex         sync.Mutex
+// IsNodeError returns false for not found and json rpc error, which are answered by a working node.
+func (n *ethereumNode) IsNodeError(err error) bool {
+	if _, ok := err.(rpc.Error); ok {
+		return false
+	}
+	return err != ethereum.NotFound
 }
 
-func NewEthereumSdkPro(urls []string, slot uint64, id uint64) *EthereumSdkPro {
-	infos := make(map[string]*EthereumInfo, len(urls))
-	for _, url := range urls {
-		infos[url] = NewEthereumInfo(url)
-	}
-	pro := &EthereumSdkPro{infos: infos, selectionSlot: slot, id: id}
-	pro.selection()
-	go pro.NodeSelection()
-	return pro
+func (n *ethereumNode) Close() {
+	n.sdk.Close()
 }
 
-func (pro *EthereumSdkPro) NodeSelection() {
-	for {
-		pro.nodeSelection()
-	}
+type EthereumSdkPro struct {
+	pool *Pool
 }
 
-func (pro *EthereumSdkPro) nodeSelection() {
-	defer func() {
-		if r := recover(); r != nil {
-			logs.Error("node selection, recover info: %s", string(debug.Stack()))
-		}
-	}()
-	logs.Debug("node selection of chain : %d......", pro.id)
-	ticker := time.NewTicker(time.Second * time.Duration(pro.selectionSlot))
-	for {
-		select {
-		case <-ticker.C:
-			pro.selection()
+func NewEthereumSdkPro(urls []string, slot uint64, id uint64) *EthereumSdkPro {
+	nodes := make(map[string]PoolNode, len(urls))
+	for _, url := range urls {
+		sdk, err := NewEthereumSdk(url)
+		if err != nil || sdk == nil {
+			panic(err)
 		}
+		nodes[url] = &ethereumNode{sdk: sdk}
 	}
+	return &EthereumSdkPro{pool: NewPool(nodes, slot, id)}
 }
 
-func (pro *EthereumSdkPro) selection() {
-	for url, info := range pro.infos {
-		height, err := info.sdk.GetCurrentBlockHeight()
-		if err != nil || height == math.MaxUint64 {
-			logs.Error("get current block height err: %v, url: %s", err, url)
-			height = 1
-		}
-		/*
-			block, err := info.sdk.GetBlockByNumber(height)
-			if err != nil || block == nil {
-				logs.Error("get current block err: %v, url: %s", err, url)
-				info.latestHeight = height - 1
-				continue
-			}
-			transactions := block.Transactions()
-			if len(transactions) > 0 {
-				transaction := transactions[0]
-				receipt, err := info.sdk.GetTransactionReceipt(transaction.Hash())
-				if err != nil || receipt == nil {
-					logs.Error("get transaction receipt err: %v, url: %s", err, url)
-					info.latestHeight = height - 1
-					continue
-				}
-			}
-		*/
-		pro.mutex.Lock()
-		info.latestHeight = height - 1
-		pro.mutex.Unlock()
-	}
+// Close stops the node selection goroutine and closes connections of all nodes.
+func (pro *EthereumSdkPro) Close() {
+	pro.pool.Close()
 }
 
-func (pro *EthereumSdkPro) GetLatest() *EthereumInfo {
-	pro.mutex.Lock()
-	defer func() {
-		pro.mutex.Unlock()
-	}()
-	height := uint64(0)
-	var latestInfo *EthereumInfo = nil
-	for _, info := range pro.infos {
-		if info != nil && info.latestHeight > height {
-			height = info.latestHeight
-			latestInfo = info
-		}
-	}
-	return latestInfo
+func (pro *EthereumSdkPro) call(ctx context.Context, fn func(sdk *EthereumSdk) error) error {
+	return pro.pool.Call(ctx, func(node PoolNode) error {
+		return fn(node.(*ethereumNode).sdk)
+	})
 }
 
 func (pro *EthereumSdkPro) GetClient() *ethclient.Client {
-	info := pro.GetLatest()
-	if info == nil {
+	node := pro.pool.Latest()
+	if node == nil {
 		return nil
 	}
-	return info.sdk.GetClient()
+	return node.(*ethereumNode).sdk.GetClient()
 }
 
 func (pro *EthereumSdkPro) GetLatestHeight() (uint64, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return 0, fmt.Errorf("all node is not working")
-	}
-	return info.latestHeight, nil
+	return pro.pool.LatestHeight()
 }
 
-func (pro *EthereumSdkPro) GetHeaderByNumber(number uint64) (*types.Header, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, fmt.Errorf("all node is not working")
-	}
-
-	for info != nil {
-		header, err := info.sdk.GetHeaderByNumber(number)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return header, nil
-		}
-	}
-	return nil, fmt.Errorf("all node is not working")
+func (pro *EthereumSdkPro) GetHeaderByNumber(ctx context.Context, number uint64) (header *types.Header, err error) {
+	err = pro.call(ctx, func(sdk *EthereumSdk) error {
+		header, err = sdk.GetHeaderByNumber(ctx, number)
+		return err
+	})
+	return
 }
 
-func (pro *EthereumSdkPro) GetTransactionByHash(hash common.Hash) (*types.Transaction, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, fmt.Errorf("all node is not working")
-	}
-
-	for info != nil {
-		tx, err := info.sdk.GetTransactionByHash(hash)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return tx, nil
-		}
-	}
-	return nil, fmt.Errorf("all node is not working")
+func (pro *EthereumSdkPro) GetRawHeaderByNumber(ctx context.Context, number uint64) (header *RawHeader, err error) {
+	err = pro.call(ctx, func(sdk *EthereumSdk) error {
+		header, err = sdk.GetRawHeaderByNumber(ctx, number)
+		return err
+	})
+	return
 }
 
-func (pro *EthereumSdkPro) GetTransactionReceipt(hash common.Hash) (*types.Receipt, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, fmt.Errorf("all node is not working")
-	}
-
-	for info != nil {
-		receipt, err := info.sdk.GetTransactionReceipt(hash)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return receipt, nil
-		}
-	}
-	return nil, fmt.Errorf("all node is not working")
+func (pro *EthereumSdkPro) GetTransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, err error) {
+	err = pro.call(ctx, func(sdk *EthereumSdk) error {
+		tx, err = sdk.GetTransactionByHash(ctx, hash)
+		return err
+	})
+	return
 }
 
-func (pro *EthereumSdkPro) NonceAt(addr common.Address) (uint64, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return 0, fmt.Errorf("all node is not working")
-	}
-
-	for info != nil {
-		nonce, err := info.sdk.NonceAt(addr)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return nonce, nil
-		}
-	}
-	return 0, fmt.Errorf("all node is not working")
+func (pro *EthereumSdkPro) GetTransactionReceipt(ctx context.Context, hash common.Hash) (receipt *types.Receipt, err error) {
+	err = pro.call(ctx, func(sdk *EthereumSdk) error {
+		receipt, err = sdk.GetTransactionReceipt(ctx, hash)
+		return err
+	})
+	return
 }
 
-func (pro *EthereumSdkPro) SendRawTransaction(tx *types.Transaction) error {
-	info := pro.GetLatest()
-	if info == nil {
-		return fmt.Errorf("all node is not working")
-	}
-
-	for info != nil {
-		err := info.sdk.SendRawTransaction(tx)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return nil
-		}
-	}
-	return fmt.Errorf("all node is not working")
+func (pro *EthereumSdkPro) NonceAt(ctx context.Context, addr common.Address) (nonce uint64, err error) {
+	err = pro.call(ctx, func(sdk *EthereumSdk) error {
+		nonce, err = sdk.NonceAt(ctx, addr)
+		return err
+	})
+	return
 }
 
-func (pro *EthereumSdkPro) TransactionByHash(hash common.Hash) (*types.Transaction, bool, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, false, fmt.Errorf("all node is not working")
-	}
-
-	for info != nil {
-		tx, isPending, err := info.sdk.TransactionByHash(hash)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return tx, isPending, nil
-		}
-	}
-	return nil, false, fmt.Errorf("all node is not working")
+// SendRawTransaction broadcasts the transaction, an *TxSendError returned if no node accepted it.
+func (pro *EthereumSdkPro) SendRawTransaction(ctx context.Context, tx *types.Transaction) error {
+	return pro.BroadcastTransaction(ctx, tx).Err()
 }
 
-func (pro *EthereumSdkPro) SuggestGasPrice() (*big.Int, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, fmt.Errorf("all node is not working")
-	}
+// BroadcastTransaction sends the signed transaction to every healthy node in parallel.
+func (pro *EthereumSdkPro) BroadcastTransaction(ctx context.Context, tx *types.Transaction) BroadcastResult {
+	return pro.pool.Broadcast(ctx, func(node PoolNode) error {
+		return node.(*ethereumNode).sdk.SendRawTransaction(ctx, tx)
+	})
+}
 
-	for info != nil {
-		gas, err := info.sdk.SuggestGasPrice()
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return gas, nil
-		}
-	}
-	return nil, fmt.Errorf("all node is not working")
+func (pro *EthereumSdkPro) TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error) {
+	err = pro.call(ctx, func(sdk *EthereumSdk) error {
+		tx, isPending, err = sdk.TransactionByHash(ctx, hash)
+		return err
+	})
+	return
 }
 
-func (pro *EthereumSdkPro) EstimateGas(msg ethereum.CallMsg) (uint64, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return 0, fmt.Errorf("all node is not working")
-	}
+func (pro *EthereumSdkPro) SuggestGasPrice(ctx context.Context) (gasPrice *big.Int, err error) {
+	err = pro.call(ctx, func(sdk *EthereumSdk) error {
+		gasPrice, err = sdk.SuggestGasPrice(ctx)
+		return err
+	})
+	return
+}
 
-	for info != nil {
-		gas, err := info.sdk.EstimateGas(msg)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return gas, nil
-		}
-	}
-	return 0, fmt.Errorf("all node is not working")
+func (pro *EthereumSdkPro) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (gas uint64, err error) {
+	err = pro.call(ctx, func(sdk *EthereumSdk) error {
+		gas, err = sdk.EstimateGas(ctx, msg)
+		return err
+	})
+	return
 }
 
-func (pro *EthereumSdkPro) WaitTransactionConfirm(hash common.Hash) bool {
+func (pro *EthereumSdkPro) WaitTransactionConfirm(ctx context.Context, hash common.Hash) bool {
 	num := 0
 	for num < 300 {
-		time.Sleep(time.Second * 2)
-		_, ispending, err := pro.TransactionByHash(hash)
+		select {
+		case <-time.After(time.Second * 2):
+		case <-ctx.Done():
+			return false
+		}
+		_, ispending, err := pro.TransactionByHash(ctx, hash)
 		if err != nil {
 			num++
 			continue
@@ -313,8 +186,3 @@ func (pro *EthereumSdkPro) WaitTransactionConfirm(hash common.Hash) bool {
 	}
 	return false
 }
-
-func (pro *EthereumSdkPro) reset(info *EthereumInfo) *EthereumInfo {
-	info.latestHeight = 0
-	return pro.GetLatest()
-}
@@ -0,0 +1,233 @@
+package chainsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// rpcStub is a minimal ethereum json rpc node whose height, health and latency can be changed in tests.
+type rpcStub struct {
+	server  *httptest.Server
+	height  uint64
+	failing int32
+	delay   int64
+	calls   int64
+	sendErr atomic.Value
+}
+
+func newRpcStub(height uint64) *rpcStub {
+	stub := &rpcStub{height: height}
+	stub.server = httptest.NewServer(http.HandlerFunc(stub.serve))
+	return stub
+}
+
+func (stub *rpcStub) serve(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt64(&stub.calls, 1)
+	if delay := atomic.LoadInt64(&stub.delay); delay > 0 {
+		time.Sleep(time.Duration(delay))
+	}
+	if atomic.LoadInt32(&stub.failing) == 1 {
+		http.Error(w, "node is down", http.StatusInternalServerError)
+		return
+	}
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var result interface{}
+	switch req.Method {
+	case "eth_blockNumber":
+		result = fmt.Sprintf("0x%x", atomic.LoadUint64(&stub.height))
+	case "eth_getTransactionCount":
+		result = "0x7"
+	case "eth_gasPrice":
+		result = "0x3b9aca00"
+	case "eth_sendRawTransaction":
+		if msg, _ := stub.sendErr.Load().(string); msg != "" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":%q}}`, req.ID, msg)
+			return
+		}
+		result = common.Hash{}.Hex()
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"method not found"}}`, req.ID)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func (stub *rpcStub) setHeight(height uint64) { atomic.StoreUint64(&stub.height, height) }
+func (stub *rpcStub) setFailing(failing bool) {
+	if failing {
+		atomic.StoreInt32(&stub.failing, 1)
+	} else {
+		atomic.StoreInt32(&stub.failing, 0)
+	}
+}
+func (stub *rpcStub) setSendErr(msg string)        { stub.sendErr.Store(msg) }
+func (stub *rpcStub) setDelay(delay time.Duration) { atomic.StoreInt64(&stub.delay, int64(delay)) }
+func (stub *rpcStub) url() string                  { return stub.server.URL }
+
+func newTestEthereumSdkPro(t *testing.T, stubs ...*rpcStub) *EthereumSdkPro {
+	urls := make([]string, 0, len(stubs))
+	for _, stub := range stubs {
+		urls = append(urls, stub.url())
+		t.Cleanup(stub.server.Close)
+	}
+	pro := NewEthereumSdkPro(urls, 3600, 2)
+	pro.pool.breakerBackoff = 50 * time.Millisecond
+	pro.pool.breakerMax = 200 * time.Millisecond
+	t.Cleanup(pro.Close)
+	return pro
+}
+
+func selectedUrl(pool *Pool) string {
+	n := pool.selectNode(nil)
+	if n == nil {
+		return ""
+	}
+	return n.url
+}
+
+func TestEthereumSdkPro_LagTolerance(t *testing.T) {
+	fast := newRpcStub(100)
+	lagging := newRpcStub(90)
+	pro := newTestEthereumSdkPro(t, fast, lagging)
+
+	for i := 0; i < 10; i++ {
+		if url := selectedUrl(pro.pool); url != fast.url() {
+			t.Fatalf("lagging node should not be selected, got %s", url)
+		}
+	}
+	height, err := pro.GetLatestHeight()
+	if err != nil || height != 100 {
+		t.Fatalf("latest height expected 100, got %d, err: %v", height, err)
+	}
+
+	// a node within tolerance but with much lower latency is preferred
+	lagging.setHeight(100 - DefaultLagTolerance)
+	fast.setDelay(30 * time.Millisecond)
+	for i := 0; i < 5; i++ {
+		pro.pool.selection()
+	}
+	if url := selectedUrl(pro.pool); url != lagging.url() {
+		t.Fatalf("faster node within lag tolerance should be selected, got %s", url)
+	}
+}
+
+func TestEthereumSdkPro_Failover(t *testing.T) {
+	a := newRpcStub(100)
+	b := newRpcStub(100)
+	pro := newTestEthereumSdkPro(t, a, b)
+
+	first := selectedUrl(pro.pool)
+	if first == a.url() {
+		a.setFailing(true)
+	} else {
+		b.setFailing(true)
+	}
+	nonce, err := pro.NonceAt(context.Background(), common.Address{})
+	if err != nil || nonce != 7 {
+		t.Fatalf("call should fail over to healthy node, nonce: %d, err: %v", nonce, err)
+	}
+
+	a.setFailing(true)
+	b.setFailing(true)
+	if _, err := pro.SuggestGasPrice(context.Background()); err == nil {
+		t.Fatalf("call should fail when all nodes are down")
+	}
+
+	// rpc errors returned by a healthy node are not node failures
+	a.setFailing(false)
+	b.setFailing(false)
+	time.Sleep(pro.pool.breakerMax)
+	pro.pool.selection()
+	if _, err := pro.EstimateGas(context.Background(), ethereum.CallMsg{}); err == nil {
+		t.Fatalf("rpc error should be returned")
+	}
+	pro.pool.mutex.Lock()
+	defer pro.pool.mutex.Unlock()
+	for _, n := range pro.pool.nodes {
+		if n.failures != 0 {
+			t.Fatalf("node %s failures expected 0, got %d", n.url, n.failures)
+		}
+	}
+}
+
+func TestEthereumSdkPro_Context(t *testing.T) {
+	a := newRpcStub(100)
+	pro := newTestEthereumSdkPro(t, a)
+
+	a.setDelay(time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := pro.NonceAt(ctx, common.Address{}); err == nil {
+		t.Fatalf("call should be cancelled by context")
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Fatalf("call should return as soon as context done")
+	}
+
+	pro.pool.mutex.Lock()
+	defer pro.pool.mutex.Unlock()
+	for _, n := range pro.pool.nodes {
+		if n.failures != 0 {
+			t.Fatalf("cancelled call should not be node failure")
+		}
+	}
+}
+
+func TestEthereumSdkPro_Broadcast(t *testing.T) {
+	a := newRpcStub(100)
+	b := newRpcStub(100)
+	c := newRpcStub(100)
+	pro := newTestEthereumSdkPro(t, a, b, c)
+	tx := types.NewTransaction(0, common.Address{}, nil, 21000, nil, nil)
+
+	a.setSendErr("nonce too low")
+	b.setFailing(true)
+	result := pro.BroadcastTransaction(context.Background(), tx)
+	if len(result) != 3 || !result.Accepted() || result.Err() != nil {
+		t.Fatalf("transaction should be accepted by one node, err: %v", result.Err())
+	}
+	statuses := make(map[string]TxStatus)
+	for _, res := range result {
+		statuses[res.Url] = res.Status
+	}
+	if statuses[a.url()] != TxNonceTooLow || statuses[b.url()] != TxNodeFailed || statuses[c.url()] != TxAccepted {
+		t.Fatalf("unexpected statuses: %v", statuses)
+	}
+
+	// already known is regarded as accepted, and is not node failure
+	b.setFailing(false)
+	a.setSendErr("already known")
+	c.setSendErr("already known")
+	if err := pro.SendRawTransaction(context.Background(), tx); err != nil {
+		t.Fatalf("already known transaction should be accepted, err: %v", err)
+	}
+
+	a.setSendErr("insufficient funds for gas * price + value")
+	b.setSendErr("insufficient funds for gas * price + value")
+	c.setFailing(true)
+	err := pro.SendRawTransaction(context.Background(), tx)
+	if sendErr, ok := err.(*TxSendError); !ok || sendErr.Status != TxInsufficientFunds {
+		t.Fatalf("insufficient funds error expected, got %v", err)
+	}
+}
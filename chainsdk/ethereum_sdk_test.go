@@ -0,0 +1,146 @@
+package chainsdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"poly-bridge/go_abi/eccm_abi"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// headerStub answers eth_getBlockByNumber with the configured header json, an extra field is added
+// like side chains do and the hash field can be replaced.
+type headerStub struct {
+	server *httptest.Server
+
+	mutex  sync.Mutex
+	header *types.Header
+	hash   *common.Hash
+	blocks []string
+}
+
+func newHeaderStub(t *testing.T, header *types.Header) *headerStub {
+	stub := &headerStub{header: header}
+	stub.server = httptest.NewServer(http.HandlerFunc(stub.serve))
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+func (stub *headerStub) serve(w http.ResponseWriter, r *http.Request) {
+	req := new(stubRequest)
+	json.NewDecoder(r.Body).Decode(req)
+	var block string
+	json.Unmarshal(req.Params[0], &block)
+
+	stub.mutex.Lock()
+	defer stub.mutex.Unlock()
+	stub.blocks = append(stub.blocks, block)
+	var result interface{}
+	if stub.header != nil {
+		fields := make(map[string]interface{})
+		enc, _ := stub.header.MarshalJSON()
+		json.Unmarshal(enc, &fields)
+		fields["sideChainField"] = "0x01"
+		if stub.hash != nil {
+			fields["hash"] = stub.hash
+		}
+		result = fields
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+}
+
+func (stub *headerStub) lastBlock() string {
+	stub.mutex.Lock()
+	defer stub.mutex.Unlock()
+	return stub.blocks[len(stub.blocks)-1]
+}
+
+func TestEthereumSdk_GetRawHeader(t *testing.T) {
+	header := &types.Header{
+		Number:     big.NewInt(0x20),
+		Difficulty: big.NewInt(2),
+		GasLimit:   8000000,
+		Time:       1600000000,
+		Extra:      []byte{1, 2, 3},
+	}
+	stub := newHeaderStub(t, header)
+	sdk, err := NewEthereumSdk(stub.server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sdk.Close()
+
+	raw, err := sdk.GetRawHeaderByNumber(context.Background(), 0x20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block := stub.lastBlock(); block != hexutil.EncodeUint64(0x20) {
+		t.Fatalf("block argument expected 0x20, got %s", block)
+	}
+	if err := raw.Verify(); err != nil || raw.Hash != header.Hash() {
+		t.Fatalf("header hash should be verified, err: %v", err)
+	}
+	if !strings.Contains(string(raw.Raw), "sideChainField") {
+		t.Fatalf("raw header should keep the fields of the node, got %s", raw.Raw)
+	}
+
+	if _, err := sdk.GetLatestHeader(context.Background()); err != nil || stub.lastBlock() != "latest" {
+		t.Fatalf("latest block should be requested, got %s, err: %v", stub.lastBlock(), err)
+	}
+
+	// side chain hashing the header differently fails verification
+	stub.mutex.Lock()
+	stub.hash = &common.Hash{1}
+	stub.mutex.Unlock()
+	raw, err = sdk.GetRawHeaderByNumber(context.Background(), 0x20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := raw.Verify(); err == nil {
+		t.Fatalf("hash mismatch should fail verification")
+	}
+
+	stub.mutex.Lock()
+	stub.header = nil
+	stub.mutex.Unlock()
+	if _, err := sdk.GetHeaderByNumber(context.Background(), 0x30); err != ethereum.NotFound {
+		t.Fatalf("missing header should return not found, err: %v", err)
+	}
+}
+
+func TestDeployData(t *testing.T) {
+	eccd := common.HexToAddress("0x0d")
+	data, err := DeployData(eccm_abi.EthCrossChainManagerABI, eccm_abi.EthCrossChainManagerBin, eccd, uint64(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := common.FromHex(eccm_abi.EthCrossChainManagerBin)
+	if len(data) != len(bin)+64 || !bytes.Equal(data[:len(bin)], bin) {
+		t.Fatalf("unexpected creation code length %d", len(data))
+	}
+	if common.BytesToAddress(data[len(bin):len(bin)+32]) != eccd || data[len(data)-1] != 2 {
+		t.Fatalf("unexpected constructor args %x", data[len(bin):])
+	}
+	if _, err := DeployData(eccm_abi.EthCrossChainManagerABI, eccm_abi.EthCrossChainManagerBin, eccd); err == nil {
+		t.Fatalf("missing constructor arg should fail")
+	}
+
+	sdk, _ := newBatchTestSdk(t)
+	gas, err := sdk.EstimateDeployGas(context.Background(), eccd, eccm_abi.EthCrossChainManagerABI,
+		eccm_abi.EthCrossChainManagerBin, eccd, uint64(2))
+	if err != nil || gas != uint64(21000+len(data)) {
+		t.Fatalf("unexpected gas %d, err: %v", gas, err)
+	}
+}
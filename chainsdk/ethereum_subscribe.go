@@ -0,0 +1,225 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainsdk
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"poly-bridge/go_abi/eccm_abi"
+	"poly-bridge/go_abi/lock_proxy_abi"
+)
+
+// ResubscribeBackoff is the max back-off between resubscriptions after the connection to node is lost.
+var ResubscribeBackoff = 30 * time.Second
+
+var ErrSubscriptionNotSupported = errors.New("subscription is not supported over http, use ws or ipc endpoint")
+
+// SupportSubscription returns whether the endpoint of sdk is ws or ipc.
+func (ec *EthereumSdk) SupportSubscription() bool {
+	return !strings.HasPrefix(ec.url, "http://") && !strings.HasPrefix(ec.url, "https://")
+}
+
+// SubscribeNewHead delivers new headers to sink, the subscription is re-established when the connection
+// is lost until it's unsubscribed.
+func (ec *EthereumSdk) SubscribeNewHead(sink chan<- *types.Header) (event.Subscription, error) {
+	if !ec.SupportSubscription() {
+		return nil, ErrSubscriptionNotSupported
+	}
+	return event.Resubscribe(ResubscribeBackoff, func(ctx context.Context) (event.Subscription, error) {
+		return ec.rawClient.SubscribeNewHead(ctx, sink)
+	}), nil
+}
+
+// SubscribeLogs delivers logs matching query to sink. When the connection is lost, the subscription is
+// re-established and logs since the last delivered one are filtered from node, so no log is missed or
+// delivered twice. Removed logs of a reorg are delivered and the logs of the new canonical blocks
+// since the removed one are delivered again. FromBlock and ToBlock of query are ignored.
+func (ec *EthereumSdk) SubscribeLogs(query ethereum.FilterQuery, sink chan<- types.Log) (event.Subscription, error) {
+	if !ec.SupportSubscription() {
+		return nil, ErrSubscriptionNotSupported
+	}
+	query.FromBlock, query.ToBlock = nil, nil
+	var (
+		delivered        bool
+		lastBlock, index uint64
+	)
+	isNew := func(log types.Log) bool {
+		return !delivered || log.BlockNumber > lastBlock || log.BlockNumber == lastBlock && uint64(log.Index) > index
+	}
+	return event.Resubscribe(ResubscribeBackoff, func(ctx context.Context) (event.Subscription, error) {
+		logs := make(chan types.Log)
+		sub, err := ec.rawClient.SubscribeFilterLogs(ctx, query, logs)
+		if err != nil {
+			return nil, err
+		}
+		var missed []types.Log
+		if delivered {
+			backfill := query
+			backfill.FromBlock = new(big.Int).SetUint64(lastBlock)
+			if missed, err = ec.rawClient.FilterLogs(ctx, backfill); err != nil {
+				sub.Unsubscribe()
+				return nil, err
+			}
+		}
+		return event.NewSubscription(func(quit <-chan struct{}) error {
+			defer sub.Unsubscribe()
+			deliver := func(log types.Log) bool {
+				if !log.Removed && !isNew(log) {
+					return true
+				}
+				select {
+				case sink <- log:
+				case <-quit:
+					return false
+				}
+				switch {
+				case !log.Removed:
+					delivered, lastBlock, index = true, log.BlockNumber, uint64(log.Index)
+				case !delivered || log.BlockNumber > lastBlock:
+				case log.BlockNumber == 0:
+					delivered = false
+				default:
+					// the block is reorganized, all logs of the new block are new
+					lastBlock, index = log.BlockNumber-1, math.MaxUint64
+				}
+				return true
+			}
+			for _, log := range missed {
+				if !deliver(log) {
+					return nil
+				}
+			}
+			for {
+				select {
+				case log := <-logs:
+					if !deliver(log) {
+						return nil
+					}
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			}
+		}), nil
+	}), nil
+}
+
+// watchEvent subscribes the logs of event `name` emitted by contract, each log is decoded and delivered
+// by parse, which should give up when quit is closed.
+func (ec *EthereumSdk) watchEvent(contract common.Address, contractAbi string, name string,
+	parse func(log types.Log, quit <-chan struct{}) error) (event.Subscription, error) {
+	parsed, err := abi.JSON(strings.NewReader(contractAbi))
+	if err != nil {
+		return nil, err
+	}
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{contract},
+		Topics:    [][]common.Hash{{parsed.Events[name].ID}},
+	}
+	logs := make(chan types.Log)
+	sub, err := ec.SubscribeLogs(query, logs)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				if err := parse(log, quit); err != nil {
+					return err
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// WatchCrossChainEvent subscribes CrossChainEvent of ECCM with automatic resubscription.
+func (ec *EthereumSdk) WatchCrossChainEvent(eccm common.Address,
+	sink chan<- *eccm_abi.EthCrossChainManagerCrossChainEvent) (event.Subscription, error) {
+	filterer, err := eccm_abi.NewEthCrossChainManagerFilterer(eccm, ec.rawClient)
+	if err != nil {
+		return nil, err
+	}
+	return ec.watchEvent(eccm, eccm_abi.EthCrossChainManagerABI, "CrossChainEvent", func(log types.Log, quit <-chan struct{}) error {
+		evt, err := filterer.ParseCrossChainEvent(log)
+		if err != nil {
+			return err
+		}
+		select {
+		case sink <- evt:
+		case <-quit:
+		}
+		return nil
+	})
+}
+
+// WatchLockEvent subscribes LockEvent of LockProxy with automatic resubscription.
+func (ec *EthereumSdk) WatchLockEvent(lockProxy common.Address,
+	sink chan<- *lock_proxy_abi.LockProxyLockEvent) (event.Subscription, error) {
+	filterer, err := lock_proxy_abi.NewLockProxyFilterer(lockProxy, ec.rawClient)
+	if err != nil {
+		return nil, err
+	}
+	return ec.watchEvent(lockProxy, lock_proxy_abi.LockProxyABI, "LockEvent", func(log types.Log, quit <-chan struct{}) error {
+		evt, err := filterer.ParseLockEvent(log)
+		if err != nil {
+			return err
+		}
+		select {
+		case sink <- evt:
+		case <-quit:
+		}
+		return nil
+	})
+}
+
+// WatchUnlockEvent subscribes UnlockEvent of LockProxy with automatic resubscription.
+func (ec *EthereumSdk) WatchUnlockEvent(lockProxy common.Address,
+	sink chan<- *lock_proxy_abi.LockProxyUnlockEvent) (event.Subscription, error) {
+	filterer, err := lock_proxy_abi.NewLockProxyFilterer(lockProxy, ec.rawClient)
+	if err != nil {
+		return nil, err
+	}
+	return ec.watchEvent(lockProxy, lock_proxy_abi.LockProxyABI, "UnlockEvent", func(log types.Log, quit <-chan struct{}) error {
+		evt, err := filterer.ParseUnlockEvent(log)
+		if err != nil {
+			return err
+		}
+		select {
+		case sink <- evt:
+		case <-quit:
+		}
+		return nil
+	})
+}
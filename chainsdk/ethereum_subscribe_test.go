@@ -0,0 +1,253 @@
+package chainsdk
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"poly-bridge/go_abi/lock_proxy_abi"
+)
+
+// logService is the eth namespace of a websocket stub node which supports logs subscription and eth_getLogs.
+type logService struct {
+	mutex      sync.Mutex
+	logs       []types.Log
+	notifiers  map[*rpc.Notifier]*rpc.Subscription
+	subscribed chan struct{}
+}
+
+func (s *logService) Logs(ctx context.Context, crit map[string]interface{}) (*rpc.Subscription, error) {
+	notifier, _ := rpc.NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	s.mutex.Lock()
+	s.notifiers[notifier] = sub
+	s.mutex.Unlock()
+	s.subscribed <- struct{}{}
+	return sub, nil
+}
+
+func (s *logService) GetLogs(ctx context.Context, crit map[string]interface{}) ([]types.Log, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.logs, nil
+}
+
+// add stores the log for eth_getLogs, and notifies subscribers if notify.
+func (s *logService) add(log types.Log, notify bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.logs = append(s.logs, log)
+	if notify {
+		for notifier, sub := range s.notifiers {
+			notifier.Notify(sub.ID, log)
+		}
+	}
+}
+
+type wsStub struct {
+	service *logService
+	server  *httptest.Server
+	rpc     atomic.Value
+}
+
+func newWsStub(t *testing.T) *wsStub {
+	stub := &wsStub{service: &logService{
+		notifiers:  make(map[*rpc.Notifier]*rpc.Subscription),
+		subscribed: make(chan struct{}, 8),
+	}}
+	stub.restart()
+	stub.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.rpc.Load().(*rpc.Server).WebsocketHandler([]string{"*"}).ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		stub.rpc.Load().(*rpc.Server).Stop()
+		stub.server.Close()
+	})
+	return stub
+}
+
+// restart drops all connections of the stub, clients have to reconnect.
+func (stub *wsStub) restart() {
+	if old, ok := stub.rpc.Load().(*rpc.Server); ok {
+		old.Stop()
+	}
+	stub.service.mutex.Lock()
+	stub.service.notifiers = make(map[*rpc.Notifier]*rpc.Subscription)
+	stub.service.mutex.Unlock()
+	server := rpc.NewServer()
+	server.RegisterName("eth", stub.service)
+	stub.rpc.Store(server)
+}
+
+func (stub *wsStub) url() string {
+	return "ws" + strings.TrimPrefix(stub.server.URL, "http")
+}
+
+func (stub *wsStub) waitSubscribed(t *testing.T) {
+	select {
+	case <-stub.service.subscribed:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("subscription should be established")
+	}
+}
+
+func receiveLog(t *testing.T, logs <-chan types.Log) types.Log {
+	select {
+	case log := <-logs:
+		return log
+	case <-time.After(5 * time.Second):
+		t.Fatalf("log should be received")
+	}
+	return types.Log{}
+}
+
+func newTestLog(block uint64, index uint, data []byte) types.Log {
+	return types.Log{
+		Address:     common.HexToAddress("0x01"),
+		Topics:      []common.Hash{},
+		Data:        data,
+		BlockNumber: block,
+		TxHash:      common.BigToHash(new(big.Int).SetUint64(block)),
+		BlockHash:   common.BigToHash(new(big.Int).SetUint64(block)),
+		Index:       index,
+	}
+}
+
+func TestEthereumSdk_SubscribeLogs(t *testing.T) {
+	defer func(backoff time.Duration) { ResubscribeBackoff = backoff }(ResubscribeBackoff)
+	ResubscribeBackoff = 100 * time.Millisecond
+
+	stub := newWsStub(t)
+	sdk, err := NewEthereumSdk(stub.url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sdk.Close()
+
+	logs := make(chan types.Log)
+	sub, err := sdk.SubscribeLogs(ethereum.FilterQuery{}, logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	stub.waitSubscribed(t)
+
+	stub.service.add(newTestLog(1, 0, nil), true)
+	if log := receiveLog(t, logs); log.BlockNumber != 1 {
+		t.Fatalf("log of block 1 expected, got %d", log.BlockNumber)
+	}
+
+	// logs emitted while disconnected are filtered after resubscription, without duplication
+	stub.restart()
+	stub.service.add(newTestLog(2, 0, nil), false)
+	stub.waitSubscribed(t)
+	if log := receiveLog(t, logs); log.BlockNumber != 2 {
+		t.Fatalf("missed log of block 2 expected, got %d", log.BlockNumber)
+	}
+	stub.service.add(newTestLog(3, 1, nil), true)
+	if log := receiveLog(t, logs); log.BlockNumber != 3 || log.Index != 1 {
+		t.Fatalf("log of block 3 expected, got %d", log.BlockNumber)
+	}
+}
+
+func TestEthereumSdk_SubscribeLogsReorg(t *testing.T) {
+	stub := newWsStub(t)
+	sdk, err := NewEthereumSdk(stub.url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sdk.Close()
+
+	logs := make(chan types.Log)
+	sub, err := sdk.SubscribeLogs(ethereum.FilterQuery{}, logs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	stub.waitSubscribed(t)
+
+	stub.service.add(newTestLog(4, 2, nil), true)
+	stub.service.add(newTestLog(5, 3, nil), true)
+	receiveLog(t, logs)
+	receiveLog(t, logs)
+
+	// blocks 4 and 5 are reorganized, logs of the new blocks have lower index
+	for _, block := range []uint64{5, 4} {
+		removed := newTestLog(block, uint(block-2), nil)
+		removed.Removed = true
+		stub.service.add(removed, true)
+		if log := receiveLog(t, logs); !log.Removed || log.BlockNumber != block {
+			t.Fatalf("removed log of block %d expected, got %+v", block, log)
+		}
+	}
+	for _, expect := range []types.Log{newTestLog(4, 0, []byte{1}), newTestLog(5, 1, []byte{1})} {
+		stub.service.add(expect, true)
+		if log := receiveLog(t, logs); log.Removed || log.BlockNumber != expect.BlockNumber || log.Index != expect.Index {
+			t.Fatalf("log of new block %d index %d expected, got %+v", expect.BlockNumber, expect.Index, log)
+		}
+	}
+}
+
+func TestEthereumSdk_WatchLockEvent(t *testing.T) {
+	stub := newWsStub(t)
+	sdk, err := NewEthereumSdk(stub.url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sdk.Close()
+
+	events := make(chan *lock_proxy_abi.LockProxyLockEvent)
+	sub, err := sdk.WatchLockEvent(common.HexToAddress("0x01"), events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+	stub.waitSubscribed(t)
+
+	parsed, err := abi.JSON(strings.NewReader(lock_proxy_abi.LockProxyABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lockEvent := parsed.Events["LockEvent"]
+	data, err := lockEvent.Inputs.Pack(common.HexToAddress("0x0a"), common.HexToAddress("0x0b"), uint64(2),
+		common.HexToAddress("0x0c").Bytes(), common.HexToAddress("0x0d").Bytes(), big.NewInt(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := newTestLog(1, 0, data)
+	log.Topics = []common.Hash{lockEvent.ID}
+	stub.service.add(log, true)
+
+	select {
+	case evt := <-events:
+		if evt.ToChainId != 2 || evt.Amount.Int64() != 100 || evt.FromAssetHash != common.HexToAddress("0x0a") {
+			t.Fatalf("unexpected lock event %+v", evt)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("lock event should be received")
+	}
+}
+
+func TestEthereumSdk_SubscribeOverHttp(t *testing.T) {
+	stub := newRpcStub(100)
+	defer stub.server.Close()
+	sdk, err := NewEthereumSdk(stub.url())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sdk.Close()
+	if _, err := sdk.SubscribeNewHead(make(chan *types.Header)); err != ErrSubscriptionNotSupported {
+		t.Fatalf("subscription over http should not be supported, err: %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainsdk
+
+import (
+	"net/http"
+
+	"poly-bridge/utils/metrics"
+)
+
+var (
+	poolNodeHeight = metrics.DefaultRegistry.NewGaugeVec("chainsdk_pool_node_height",
+		"Latest block height probed from the node.", "chain", "node")
+	poolNodeLag = metrics.DefaultRegistry.NewGaugeVec("chainsdk_pool_node_lag",
+		"Blocks the node falls behind the best node of the pool.", "chain", "node")
+	poolNodeUp = metrics.DefaultRegistry.NewGaugeVec("chainsdk_pool_node_up",
+		"Whether the node is available for selection, 0 if it is cut off or not working.", "chain", "node")
+	poolRequests = metrics.DefaultRegistry.NewCounterVec("chainsdk_pool_requests_total",
+		"Requests sent to the node, including height probes.", "chain", "node")
+	poolErrors = metrics.DefaultRegistry.NewCounterVec("chainsdk_pool_errors_total",
+		"Requests failed because the node is not working.", "chain", "node")
+	poolFailovers = metrics.DefaultRegistry.NewCounterVec("chainsdk_pool_failovers_total",
+		"Calls failed over to another node.", "chain")
+	poolLatency = metrics.DefaultRegistry.NewHistogramVec("chainsdk_pool_request_duration_seconds",
+		"Latency of requests sent to the node.", nil, "chain", "node")
+
+	ethTxSent = metrics.DefaultRegistry.NewCounterVec("chainsdk_ethereum_tx_sent_total",
+		"Transactions sent by EthereumSdk.", "node")
+	ethTxConfirmed = metrics.DefaultRegistry.NewCounterVec("chainsdk_ethereum_tx_confirmed_total",
+		"Transactions sent by EthereumSdk and executed successfully.", "node")
+	ethTxReverted = metrics.DefaultRegistry.NewCounterVec("chainsdk_ethereum_tx_reverted_total",
+		"Transactions sent by EthereumSdk and reverted.", "node")
+	ethTxConfirmDuration = metrics.DefaultRegistry.NewHistogramVec("chainsdk_ethereum_tx_confirm_duration_seconds",
+		"Time from sending a transaction to it is packed.", []float64{1, 2, 5, 10, 15, 30, 60, 120, 300}, "node")
+)
+
+// MetricsHandler serves metrics of chainsdk in prometheus text format.
+func MetricsHandler() http.Handler {
+	return metrics.DefaultRegistry.Handler()
+}
+
+// ServeMetrics exposes metrics of chainsdk on `/metrics` of `addr`, it's used by the long-running
+// service which embeds chainsdk.
+func ServeMetrics(addr string) (*http.Server, error) {
+	return metrics.Serve(addr, metrics.DefaultRegistry)
+}
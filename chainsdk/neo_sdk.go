@@ -18,6 +18,7 @@
 package chainsdk
 
 import (
+	"context"
 	"fmt"
 	"github.com/joeqian10/neo-gogogo/helper"
 	"github.com/joeqian10/neo-gogogo/nep5"
@@ -37,47 +38,80 @@ func NewNeoSdk(url string) *NeoSdk {
 	}
 }
 
-func (sdk *NeoSdk) GetBlockCount() (uint64, error) {
-	res := sdk.client.GetBlockCount()
+func (sdk *NeoSdk) GetBlockCount(ctx context.Context) (uint64, error) {
+	value, err := callWithContext(ctx, func() (interface{}, error) {
+		return sdk.client.GetBlockCount(), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	res := value.(rpc.GetBlockCountResponse)
 	if res.ErrorResponse.Error.Message != "" {
 		return 0, fmt.Errorf("%s", res.ErrorResponse.Error.Message)
 	}
 	return uint64(res.Result), nil
 }
 
-func (sdk *NeoSdk) GetBlockByIndex(index uint64) (*models.RpcBlock, error) {
-	res := sdk.client.GetBlockByIndex(uint32(index))
+func (sdk *NeoSdk) GetBlockByIndex(ctx context.Context, index uint64) (*models.RpcBlock, error) {
+	value, err := callWithContext(ctx, func() (interface{}, error) {
+		return sdk.client.GetBlockByIndex(uint32(index)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := value.(rpc.GetBlockResponse)
 	if res.ErrorResponse.Error.Message != "" {
 		return nil, fmt.Errorf("%s", res.ErrorResponse.Error.Message)
 	}
 	return &res.Result, nil
 }
 
-func (sdk *NeoSdk) GetApplicationLog(txId string) (*models.RpcApplicationLog, error) {
-	res := sdk.client.GetApplicationLog(txId)
+func (sdk *NeoSdk) GetApplicationLog(ctx context.Context, txId string) (*models.RpcApplicationLog, error) {
+	value, err := callWithContext(ctx, func() (interface{}, error) {
+		return sdk.client.GetApplicationLog(txId), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res := value.(rpc.GetApplicationLogResponse)
 	if res.ErrorResponse.Error.Message != "" {
 		return nil, fmt.Errorf("%s", res.ErrorResponse.Error.Message)
 	}
 	return &res.Result, nil
 }
 
-func (sdk *NeoSdk) GetTransactionHeight(hash string) (uint64, error) {
-	res := sdk.client.GetTransactionHeight(hash)
+func (sdk *NeoSdk) GetTransactionHeight(ctx context.Context, hash string) (uint64, error) {
+	value, err := callWithContext(ctx, func() (interface{}, error) {
+		return sdk.client.GetTransactionHeight(hash), nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	res := value.(rpc.GetTransactionHeightResponse)
 	if res.ErrorResponse.Error.Message != "" {
 		return 0, fmt.Errorf("%s", res.ErrorResponse.Error.Message)
 	}
 	return uint64(res.Result), nil
 }
 
-func (sdk *NeoSdk) SendRawTransaction(txHex string) (bool, error) {
-	res := sdk.client.SendRawTransaction(txHex)
+func (sdk *NeoSdk) SendRawTransaction(ctx context.Context, txHex string) (bool, error) {
+	value, err := callWithContext(ctx, func() (interface{}, error) {
+		return sdk.client.SendRawTransaction(txHex), nil
+	})
+	if err != nil {
+		return false, err
+	}
+	res := value.(rpc.SendRawTransactionResponse)
 	if res.HasError() {
 		return false, fmt.Errorf("%s", res.ErrorResponse.Error.Message)
 	}
 	return res.Result, nil
 }
 
-func (sdk *NeoSdk) Nep5Info(hash string) (string, string, int64, error) {
+func (sdk *NeoSdk) Nep5Info(ctx context.Context, hash string) (string, string, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return "", "", 0, err
+	}
 	scriptHash, err := helper.UInt160FromString(hash)
 	if err != nil {
 		return "", "", 0, err
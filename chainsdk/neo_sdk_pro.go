@@ -18,194 +18,121 @@
 package chainsdk
 
 import (
-	"fmt"
-	"github.com/astaxie/beego/logs"
-	"github.com/joeqian10/neo-gogogo/rpc/models"
-	"runtime/debug"
+	"context"
 	"strings"
-	"sync"
 	"time"
+
+	"github.com/joeqian10/neo-gogogo/rpc/models"
 )
 
-type NeoInfo struct {
-	sdk          *NeoSdk
-	latestHeight uint64
+type neoNode struct {
+	sdk *NeoSdk
 }
 
-func NewNeoInfo(url string) *NeoInfo {
-	sdk := NewNeoSdk(url)
-	return &NeoInfo{
-		sdk:          sdk,
-		latestHeight: 0,
-	}
+func (n *neoNode) Height(ctx context.Context) (uint64, error) {
+	return n.sdk.GetBlockCount(ctx)
 }
 
+// IsNodeError returns true for transport errors, neo sdk does not distinguish json rpc errors from
+// connection errors, so the messages are recognized.
+func (n *neoNode) IsNodeError(err error) bool {
+	return isTransportError(err)
+}
+
+func (n *neoNode) Close() {}
+
 type NeoSdkPro struct {
-	infos         map[string]*NeoInfo
-	selectionSlot uint64
-	id            uint64
-	mutex         sync.Mutex
+	pool *Pool
 }
 
 func NewNeoSdkPro(urls []string, slot uint64, id uint64) *NeoSdkPro {
-	infos := make(map[string]*NeoInfo, len(urls))
+	nodes := make(map[string]PoolNode, len(urls))
 	for _, url := range urls {
-		infos[url] = NewNeoInfo(url)
-	}
-	pro := &NeoSdkPro{infos: infos, selectionSlot: slot, id: id}
-	pro.selection()
-	go pro.NodeSelection()
-	return pro
-}
-
-func (pro *NeoSdkPro) NodeSelection() {
-	for {
-		pro.nodeSelection()
+		nodes[url] = &neoNode{sdk: NewNeoSdk(url)}
 	}
+	return &NeoSdkPro{pool: NewPool(nodes, slot, id)}
 }
 
-func (pro *NeoSdkPro) nodeSelection() {
-	defer func() {
-		if r := recover(); r != nil {
-			logs.Error("node selection, recover info: %s", string(debug.Stack()))
-		}
-	}()
-	logs.Debug("node selection of chain : %d......", pro.id)
-	ticker := time.NewTicker(time.Second * time.Duration(pro.selectionSlot))
-	for {
-		select {
-		case <-ticker.C:
-			pro.selection()
-		}
-	}
+// Close stops the node selection goroutine.
+func (pro *NeoSdkPro) Close() {
+	pro.pool.Close()
 }
 
-func (pro *NeoSdkPro) selection() {
-	for url, info := range pro.infos {
-		height, err := info.sdk.GetBlockCount()
-		if err != nil {
-			logs.Error("get current block height err: %v, url: %s", err, url)
-		}
-		pro.mutex.Lock()
-		info.latestHeight = height
-		pro.mutex.Unlock()
-	}
+func (pro *NeoSdkPro) call(ctx context.Context, fn func(sdk *NeoSdk) error) error {
+	return pro.pool.Call(ctx, func(node PoolNode) error {
+		return fn(node.(*neoNode).sdk)
+	})
 }
 
-func (pro *NeoSdkPro) GetLatest() *NeoInfo {
-	pro.mutex.Lock()
-	defer func() {
-		pro.mutex.Unlock()
-	}()
-	height := uint64(0)
-	var latestInfo *NeoInfo = nil
-	for _, info := range pro.infos {
-		if info != nil && info.latestHeight > height {
-			height = info.latestHeight
-			latestInfo = info
-		}
-	}
-	return latestInfo
+func (pro *NeoSdkPro) GetBlockCount() (uint64, error) {
+	return pro.pool.LatestHeight()
 }
 
-func (pro *NeoSdkPro) GetBlockCount() (uint64, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return 0, fmt.Errorf("all node is not working")
-	}
-	return info.latestHeight, nil
+func (pro *NeoSdkPro) GetBlockByIndex(ctx context.Context, index uint64) (block *models.RpcBlock, err error) {
+	err = pro.call(ctx, func(sdk *NeoSdk) error {
+		block, err = sdk.GetBlockByIndex(ctx, index)
+		return err
+	})
+	return
 }
 
-func (pro *NeoSdkPro) GetBlockByIndex(index uint64) (*models.RpcBlock, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, fmt.Errorf("all node is not working")
-	}
-	for info != nil {
-		block, err := info.sdk.GetBlockByIndex(index)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return block, nil
+func (pro *NeoSdkPro) GetApplicationLog(ctx context.Context, txId string) (log *models.RpcApplicationLog, err error) {
+	err = pro.call(ctx, func(sdk *NeoSdk) error {
+		log, err = sdk.GetApplicationLog(ctx, txId)
+		// application log of a transaction without notifications can not be decoded
+		if err != nil && strings.Contains(err.Error(), "json: cannot") {
+			return nil
 		}
-	}
-	return nil, fmt.Errorf("all node is not working")
+		return err
+	})
+	return
 }
 
-func (pro *NeoSdkPro) GetApplicationLog(txId string) (*models.RpcApplicationLog, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, fmt.Errorf("all node is not working")
-	}
-	for info != nil {
-		log, err := info.sdk.GetApplicationLog(txId)
-		if err != nil && !strings.Contains(err.Error(), "json: cannot") {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return log, nil
-		}
-	}
-	return nil, fmt.Errorf("all node is not working")
+func (pro *NeoSdkPro) Nep5Info(ctx context.Context, hash string) (scriptHash string, name string, decimal int64, err error) {
+	err = pro.call(ctx, func(sdk *NeoSdk) error {
+		scriptHash, name, decimal, err = sdk.Nep5Info(ctx, hash)
+		return err
+	})
+	return
 }
 
-func (pro *NeoSdkPro) Nep5Info(hash string) (string, string, int64, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return "", "", 0, fmt.Errorf("all node is not working")
-	}
-	for info != nil {
-		hash, name, decimal, err := info.sdk.Nep5Info(hash)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return hash, name, decimal, nil
-		}
-	}
-	return "", "", 0, fmt.Errorf("all node is not working")
+// GetTransactionHeight returns 0 if the transaction is not packed yet.
+func (pro *NeoSdkPro) GetTransactionHeight(ctx context.Context, hash string) (height uint64, err error) {
+	err = pro.call(ctx, func(sdk *NeoSdk) error {
+		height, err = sdk.GetTransactionHeight(ctx, hash)
+		return err
+	})
+	return
 }
 
-func (pro *NeoSdkPro) GetTransactionHeight(hash string) (uint64, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return 0, fmt.Errorf("all node is not working")
-	}
-	for info != nil {
-		height, err := info.sdk.GetTransactionHeight(hash)
-		if err != nil || height == 0 {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return height, nil
-		}
+// SendRawTransaction broadcasts the transaction, an *TxSendError returned if no node accepted it.
+func (pro *NeoSdkPro) SendRawTransaction(ctx context.Context, txHex string) (bool, error) {
+	if err := pro.BroadcastTransaction(ctx, txHex).Err(); err != nil {
+		return false, err
 	}
-	return 0, fmt.Errorf("all node is not working")
+	return true, nil
 }
 
-func (pro *NeoSdkPro) SendRawTransaction(txHex string) (bool, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return false, fmt.Errorf("all node is not working")
-	}
-	for info != nil {
-		result, err := info.sdk.SendRawTransaction(txHex)
-		if err != nil || !result {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return result, nil
+// BroadcastTransaction sends the signed transaction to every healthy node in parallel.
+func (pro *NeoSdkPro) BroadcastTransaction(ctx context.Context, txHex string) BroadcastResult {
+	return pro.pool.Broadcast(ctx, func(node PoolNode) error {
+		result, err := node.(*neoNode).sdk.SendRawTransaction(ctx, txHex)
+		if err == nil && !result {
+			return ErrTxRejected
 		}
-	}
-	return false, fmt.Errorf("all node is not working")
+		return err
+	})
 }
 
-func (pro *NeoSdkPro) WaitTransactionConfirm(hash string) bool {
+func (pro *NeoSdkPro) WaitTransactionConfirm(ctx context.Context, hash string) bool {
 	num := 0
 	for num < 150 {
-		time.Sleep(time.Second * 2)
-		height, err := pro.GetTransactionHeight(hash)
+		select {
+		case <-time.After(time.Second * 2):
+		case <-ctx.Done():
+			return false
+		}
+		height, err := pro.GetTransactionHeight(ctx, hash)
 		if err != nil || height == 0 {
 			num++
 			continue
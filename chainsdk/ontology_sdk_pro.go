@@ -18,126 +18,103 @@
 package chainsdk
 
 import (
+	"context"
 	"fmt"
-	"github.com/astaxie/beego/logs"
+
 	"github.com/ontio/ontology-go-sdk"
 	"github.com/ontio/ontology-go-sdk/common"
 	"github.com/ontio/ontology/core/types"
-	"runtime/debug"
-	"sync"
-	"time"
 )
 
-type OntologyInfo struct {
-	sdk          *ontology_go_sdk.OntologySdk
-	latestHeight uint64
+type ontologyNode struct {
+	sdk *ontology_go_sdk.OntologySdk
 }
 
-func NewOntologyInfo(url string) *OntologyInfo {
-	sdk := ontology_go_sdk.NewOntologySdk()
-	sdk.NewRpcClient().SetAddress(url)
-	return &OntologyInfo{
-		sdk:          sdk,
-		latestHeight: 0,
+func (n *ontologyNode) Height(ctx context.Context) (uint64, error) {
+	res, err := callWithContext(ctx, func() (interface{}, error) {
+		return n.sdk.GetCurrentBlockHeight()
+	})
+	if err != nil {
+		return 0, err
 	}
+	return uint64(res.(uint32)), nil
+}
+
+// IsNodeError returns true for transport errors, others are answered by a working node.
+func (n *ontologyNode) IsNodeError(err error) bool {
+	return isTransportError(err)
 }
 
+func (n *ontologyNode) Close() {}
+
 type OntologySdkPro struct {
-	infos         map[string]*OntologyInfo
-	selectionSlot uint64
-	id            uint64
-	mutex         sync.Mutex
+	pool *Pool
 }
 
 func NewOntologySdkPro(urls []string, slot uint64, id uint64) *OntologySdkPro {
-	infos := make(map[string]*OntologyInfo, len(urls))
+	nodes := make(map[string]PoolNode, len(urls))
 	for _, url := range urls {
-		infos[url] = NewOntologyInfo(url)
+		sdk := ontology_go_sdk.NewOntologySdk()
+		sdk.NewRpcClient().SetAddress(url)
+		nodes[url] = &ontologyNode{sdk: sdk}
 	}
-	pro := &OntologySdkPro{infos: infos, selectionSlot: slot, id: id}
-	pro.selection()
-	go pro.NodeSelection()
-	return pro
+	return &OntologySdkPro{pool: NewPool(nodes, slot, id)}
 }
 
-func (pro *OntologySdkPro) NodeSelection() {
-	for {
-		pro.nodeSelection()
-	}
+// Close stops the node selection goroutine.
+func (pro *OntologySdkPro) Close() {
+	pro.pool.Close()
 }
 
-func (pro *OntologySdkPro) nodeSelection() {
-	defer func() {
-		if r := recover(); r != nil {
-			logs.Error("node selection, recover info: %s", string(debug.Stack()))
-		}
-	}()
-	logs.Debug("node selection of chain : %d......", pro.id)
-	ticker := time.NewTicker(time.Second * time.Duration(pro.selectionSlot))
-	for {
-		select {
-		case <-ticker.C:
-			pro.selection()
-		}
-	}
-}
+// call returns result of `fn` answered by a working node, `fn` runs in another goroutine and may be
+// abandoned if the context is done, so it returns the result instead of writing to variables of caller.
+func (pro *OntologySdkPro) call(
+	ctx context.Context,
+	fn func(sdk *ontology_go_sdk.OntologySdk) (interface{}, error),
+) (interface{}, error) {
 
-func (pro *OntologySdkPro) selection() {
-	for url, info := range pro.infos {
-		height, err := info.sdk.GetCurrentBlockHeight()
-		if err != nil {
-			logs.Error("get current block height err: %v, url: %s", err, url)
-		}
-		pro.mutex.Lock()
-		info.latestHeight = uint64(height)
-		pro.mutex.Unlock()
+	var res interface{}
+	err := pro.pool.Call(ctx, func(node PoolNode) (err error) {
+		sdk := node.(*ontologyNode).sdk
+		res, err = callWithContext(ctx, func() (interface{}, error) {
+			return fn(sdk)
+		})
+		return
+	})
+	if err != nil {
+		return nil, err
 	}
-}
-
-func (pro *OntologySdkPro) GetLatest() *OntologyInfo {
-	pro.mutex.Lock()
-	defer func() {
-		pro.mutex.Unlock()
-	}()
-	height := uint64(0)
-	var latestInfo *OntologyInfo = nil
-	for _, info := range pro.infos {
-		if info != nil && info.latestHeight > height {
-			height = info.latestHeight
-			latestInfo = info
-		}
-	}
-	return latestInfo
+	return res, nil
 }
 
 func (pro *OntologySdkPro) GetCurrentBlockHeight() (uint64, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return 0, fmt.Errorf("all node is not working")
-	}
-	return info.latestHeight, nil
+	return pro.pool.LatestHeight()
 }
 
-func (pro *OntologySdkPro) GetBlockByHeight(height uint32) (*types.Block, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, fmt.Errorf("all node is not working")
+func (pro *OntologySdkPro) GetBlockByHeight(ctx context.Context, height uint32) (*types.Block, error) {
+	res, err := pro.call(ctx, func(sdk *ontology_go_sdk.OntologySdk) (interface{}, error) {
+		return sdk.GetBlockByHeight(height)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return info.sdk.GetBlockByHeight(height)
+	return res.(*types.Block), nil
 }
 
-func (pro *OntologySdkPro) GetSmartContractEventByBlock(height uint32) ([]*common.SmartContactEvent, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, fmt.Errorf("all node is not working")
+func (pro *OntologySdkPro) GetSmartContractEventByBlock(ctx context.Context, height uint32) ([]*common.SmartContactEvent, error) {
+	res, err := pro.call(ctx, func(sdk *ontology_go_sdk.OntologySdk) (interface{}, error) {
+		return sdk.GetSmartContractEventByBlock(height)
+	})
+	if err != nil {
+		return nil, err
 	}
-	return info.sdk.GetSmartContractEventByBlock(height)
+	return res.([]*common.SmartContactEvent), nil
 }
 
 func (pro *OntologySdkPro) GetSdk() (*ontology_go_sdk.OntologySdk, error) {
-	info := pro.GetLatest()
-	if info == nil {
+	node := pro.pool.Latest()
+	if node == nil {
 		return nil, fmt.Errorf("all node is not working")
 	}
-	return info.sdk, nil
+	return node.(*ontologyNode).sdk, nil
 }
@@ -2,6 +2,7 @@ package chainsdk
 
 import (
 	"bytes"
+	"context"
 	"crypto/elliptic"
 	"encoding/hex"
 	"encoding/json"
@@ -16,6 +17,7 @@ import (
 	"github.com/ontio/ontology-crypto/keypair"
 	"github.com/ontio/ontology-crypto/sm2"
 	polysdk "github.com/polynetwork/poly-go-sdk"
+	sdkcom "github.com/polynetwork/poly-go-sdk/common"
 	"github.com/polynetwork/poly/common"
 	vconfig "github.com/polynetwork/poly/consensus/vbft/config"
 	"github.com/polynetwork/poly/core/types"
@@ -25,9 +27,12 @@ import (
 //	block2wait uint64 = 1
 //)
 
+// PolyTxStateSuccess denotes the smart contract event state of successful transaction.
+const PolyTxStateSuccess byte = 1
+
 func NewPolySdkAndSetChainID(url string) (*PolySDK, error) {
 	s := NewPolySDK(url)
-	blk, err := s.GetBlockByHeight(0)
+	blk, err := s.GetBlockByHeight(context.Background(), 0)
 	if err != nil {
 		return nil, err
 	}
@@ -64,10 +69,6 @@ func (s *PolySDK) SyncGenesisBlock(
 		genesisHeader,
 		validators,
 	); err != nil {
-		if strings.Contains(err.Error(), "had been initialized") {
-			log.Info("side chain already synced")
-			return nil
-		}
 		return err
 	} else {
 		return s.waitPolyTx(txhash)
@@ -97,14 +98,6 @@ func (s *PolySDK) RegisterSideChain(
 		eccd,
 		owner,
 	); err != nil {
-		if strings.Contains(err.Error(), "already registered") {
-			log.Info("chain %d already registered", chainID)
-			return nil
-		}
-		if strings.Contains(err.Error(), "already requested") {
-			log.Info("chain %d already requested", chainID)
-			return nil
-		}
 		return err
 	} else {
 		return s.waitPolyTx(txhash)
@@ -136,14 +129,6 @@ func (s *PolySDK) RegisterSideChainExt(
 		extra,
 		owner,
 	); err != nil {
-		if strings.Contains(err.Error(), "already registered") {
-			log.Info("chain %d already registered", chainID)
-			return nil
-		}
-		if strings.Contains(err.Error(), "already requested") {
-			log.Info("chain %d already requested", chainID)
-			return nil
-		}
 		return err
 	} else {
 		return s.waitPolyTx(txhash)
@@ -166,6 +151,106 @@ func (s *PolySDK) ApproveRegisterSideChain(chainID uint64, validators []*polysdk
 	return s.waitPolyTx(txhash)
 }
 
+func (s *PolySDK) UpdateSideChain(
+	owner *polysdk.Account,
+	chainID,
+	blockToWait,
+	router uint64,
+	eccdAddr ecm.Address,
+	sideChainName string,
+) error {
+
+	eccd, err := hex.DecodeString(strings.Replace(eccdAddr.Hex(), "0x", "", 1))
+	if err != nil {
+		return fmt.Errorf("failed to decode eccd address, err: %s", err)
+	}
+
+	if txhash, err := s.sdk.Native.Scm.UpdateSideChain(
+		owner.Address,
+		chainID,
+		router,
+		sideChainName,
+		blockToWait,
+		eccd,
+		owner,
+	); err != nil {
+		return err
+	} else {
+		return s.waitPolyTx(txhash)
+	}
+}
+
+func (s *PolySDK) UpdateSideChainExt(
+	owner *polysdk.Account,
+	chainID,
+	blockToWait,
+	router uint64,
+	eccdAddr ecm.Address,
+	sideChainName string,
+	extra []byte,
+) error {
+
+	eccd, err := hex.DecodeString(strings.Replace(eccdAddr.Hex(), "0x", "", 1))
+	if err != nil {
+		return fmt.Errorf("failed to decode eccd address, err: %s", err)
+	}
+
+	if txhash, err := s.sdk.Native.Scm.UpdateSideChainExt(
+		owner.Address,
+		chainID,
+		router,
+		sideChainName,
+		blockToWait,
+		eccd,
+		extra,
+		owner,
+	); err != nil {
+		return err
+	} else {
+		return s.waitPolyTx(txhash)
+	}
+}
+
+func (s *PolySDK) ApproveUpdateSideChain(chainID uint64, validators []*polysdk.Account) error {
+	var (
+		txhash common.Uint256
+		err    error
+	)
+	for i, acc := range validators {
+		txhash, err = s.sdk.Native.Scm.ApproveUpdateSideChain(chainID, acc)
+		if err != nil {
+			return fmt.Errorf("no%d - failed to approve update %d: %v", i, chainID, err)
+		}
+		log.Info("No%d: successful to approve update side chain %d: ( acc: %s, txhash: %s )",
+			i, chainID, acc.Address.ToHexString(), txhash.ToHexString())
+	}
+	return s.waitPolyTx(txhash)
+}
+
+func (s *PolySDK) QuitSideChain(owner *polysdk.Account, chainID uint64) error {
+	txhash, err := s.sdk.Native.Scm.QuitSideChain(chainID, owner)
+	if err != nil {
+		return err
+	}
+	return s.waitPolyTx(txhash)
+}
+
+func (s *PolySDK) ApproveQuitSideChain(chainID uint64, validators []*polysdk.Account) error {
+	var (
+		txhash common.Uint256
+		err    error
+	)
+	for i, acc := range validators {
+		txhash, err = s.sdk.Native.Scm.ApproveQuitSideChain(chainID, acc)
+		if err != nil {
+			return fmt.Errorf("no%d - failed to approve quit %d: %v", i, chainID, err)
+		}
+		log.Info("No%d: successful to approve quit side chain %d: ( acc: %s, txhash: %s )",
+			i, chainID, acc.Address.ToHexString(), txhash.ToHexString())
+	}
+	return s.waitPolyTx(txhash)
+}
+
 func (s *PolySDK) RegisterCandidate(peer string, validator *polysdk.Account) error {
 	txHash, err := s.sdk.Native.Nm.RegisterCandidate(peer, validator)
 	if err != nil {
@@ -178,6 +263,22 @@ func (s *PolySDK) RegisterCandidate(peer string, validator *polysdk.Account) err
 	return s.waitPolyTx(txHash)
 }
 
+func (s *PolySDK) UnRegisterCandidate(peer string, signer *polysdk.Account) error {
+	txHash, err := s.sdk.Native.Nm.UnRegisterCandidate(peer, signer)
+	if err != nil {
+		return fmt.Errorf("sendTransaction error: %v", err)
+	}
+	return s.waitPolyTx(txHash)
+}
+
+func (s *PolySDK) QuitNode(peer string, signer *polysdk.Account) error {
+	txHash, err := s.sdk.Native.Nm.QuitNode(peer, signer)
+	if err != nil {
+		return fmt.Errorf("sendTransaction error: %v", err)
+	}
+	return s.waitPolyTx(txHash)
+}
+
 func (s *PolySDK) ApproveCandidate(peer string, validators []*polysdk.Account) error {
 	var (
 		txhash common.Uint256
@@ -203,28 +304,104 @@ func (s *PolySDK) CommitPolyDpos(accArr []*polysdk.Account) error {
 	return s.waitPolyTx(txhash)
 }
 
+// PolyTxTimeout denotes the max duration to wait for poly transaction confirmation.
+var PolyTxTimeout = 300 * time.Second
+
+// PolyTxError denotes that poly transaction is packed but the native contract invocation failed.
+type PolyTxError struct {
+	TxHash string
+	Height uint32
+	Notify []*sdkcom.NotifyEventInfo
+}
+
+func (e *PolyTxError) Error() string {
+	msgs := make([]string, 0, len(e.Notify))
+	for _, notify := range e.Notify {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", notify.ContractAddress, notify.States))
+	}
+	return fmt.Sprintf("poly tx %s failed at height %d, notify: [%s]", e.TxHash, e.Height, strings.Join(msgs, ", "))
+}
+
 func (s *PolySDK) waitPolyTx(hash common.Uint256) error {
-	var (
-		h         uint32
-		tick      = time.NewTicker(1 * time.Second)
-		startTime = time.Now()
-	)
+	ctx, cancel := context.WithTimeout(context.Background(), PolyTxTimeout)
+	defer cancel()
+	return s.WaitPolyTx(ctx, hash)
+}
+
+// WaitPolyTx blocks until the transaction is packed on poly chain and checks it's execution state,
+// an *PolyTxError returned if the native contract invocation failed.
+func (s *PolySDK) WaitPolyTx(ctx context.Context, hash common.Uint256) error {
+	txhash := hash.ToHexString()
+	tick := time.NewTicker(1 * time.Second)
+	defer tick.Stop()
 
-	log.Info("wait poly tx %s", hash.ToHexString())
-	for range tick.C {
-		h, _ = s.sdk.GetBlockHeightByTxHash(hash.ToHexString())
-		curr, _ := s.sdk.GetCurrentBlockHeight()
-		if h > 0 && curr > h {
-			break
+	log.Info("wait poly tx %s", txhash)
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("tx( %s ) is not confirmed, err: %v", txhash, ctx.Err())
+		case <-tick.C:
 		}
 
-		if startTime.Add(500 * time.Millisecond); startTime.Second() > 300 {
-			return fmt.Errorf("tx( %s ) is not confirm for a long time ( over %d sec )",
-				hash.ToHexString(), 300)
+		h, err := s.sdk.GetBlockHeightByTxHash(txhash)
+		if err != nil || h == 0 {
+			continue
+		}
+		curr, err := s.sdk.GetCurrentBlockHeight()
+		if err != nil || curr <= h {
+			continue
 		}
+
+		event, err := s.sdk.GetSmartContractEvent(txhash)
+		if err != nil {
+			log.Debug("failed to get poly tx %s event: %v", txhash, err)
+			continue
+		}
+		if event == nil {
+			continue
+		}
+		if event.State != PolyTxStateSuccess {
+			return &PolyTxError{TxHash: txhash, Height: h, Notify: event.Notify}
+		}
+		log.Info("poly tx %s confirmed at height %d", txhash, h)
+		return nil
 	}
+}
 
-	return nil
+// GetConsensusPeers returns the peers of vbft chain config which the latest block refers to,
+// and the height of the block in which the chain config located.
+func (s *PolySDK) GetConsensusPeers() ([]*vconfig.PeerConfig, uint64, error) {
+	curr, err := s.GetCurrentBlockHeight(context.Background())
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := s.getVbftBlockInfo(curr)
+	if err != nil {
+		return nil, 0, err
+	}
+	height := curr
+	if info.NewChainConfig == nil {
+		height = uint64(info.LastConfigBlockNum)
+		if info, err = s.getVbftBlockInfo(height); err != nil {
+			return nil, 0, err
+		}
+	}
+	if info.NewChainConfig == nil {
+		return nil, 0, fmt.Errorf("chain config not exist in block %d", height)
+	}
+	return info.NewChainConfig.Peers, height, nil
+}
+
+func (s *PolySDK) getVbftBlockInfo(height uint64) (*vconfig.VbftBlockInfo, error) {
+	block, err := s.GetBlockByHeight(context.Background(), height)
+	if err != nil {
+		return nil, err
+	}
+	info := new(vconfig.VbftBlockInfo)
+	if err := json.Unmarshal(block.Header.ConsensusPayload, info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal consensus payload of block %d, err: %s", height, err)
+	}
+	return info, nil
 }
 
 func GetBookeeper(block *types.Block) ([]keypair.PublicKey, error) {
@@ -18,6 +18,8 @@
 package chainsdk
 
 import (
+	"context"
+
 	"github.com/polynetwork/poly-go-sdk"
 	"github.com/polynetwork/poly-go-sdk/common"
 	"github.com/polynetwork/poly/core/types"
@@ -37,17 +39,32 @@ func NewPolySDK(url string) *PolySDK {
 	}
 }
 
-func (sdk *PolySDK) GetCurrentBlockHeight() (uint64, error) {
-	height, err := sdk.sdk.GetCurrentBlockHeight()
-	return uint64(height), err
+func (sdk *PolySDK) GetCurrentBlockHeight(ctx context.Context) (uint64, error) {
+	res, err := callWithContext(ctx, func() (interface{}, error) {
+		return sdk.sdk.GetCurrentBlockHeight()
+	})
+	if err != nil {
+		return 0, err
+	}
+	return uint64(res.(uint32)), nil
 }
 
-func (sdk *PolySDK) GetBlockByHeight(height uint64) (*types.Block, error) {
-	block, err := sdk.sdk.GetBlockByHeight(uint32(height))
-	return block, err
+func (sdk *PolySDK) GetBlockByHeight(ctx context.Context, height uint64) (*types.Block, error) {
+	res, err := callWithContext(ctx, func() (interface{}, error) {
+		return sdk.sdk.GetBlockByHeight(uint32(height))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(*types.Block), nil
 }
 
-func (sdk *PolySDK) GetSmartContractEventByBlock(height uint64) ([]*common.SmartContactEvent, error) {
-	event, err := sdk.sdk.GetSmartContractEventByBlock(uint32(height))
-	return event, err
+func (sdk *PolySDK) GetSmartContractEventByBlock(ctx context.Context, height uint64) ([]*common.SmartContactEvent, error) {
+	res, err := callWithContext(ctx, func() (interface{}, error) {
+		return sdk.sdk.GetSmartContractEventByBlock(uint32(height))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.([]*common.SmartContactEvent), nil
 }
@@ -18,134 +18,66 @@
 package chainsdk
 
 import (
-	"fmt"
-	"github.com/astaxie/beego/logs"
+	"context"
+
 	"github.com/polynetwork/poly-go-sdk/common"
 	"github.com/polynetwork/poly/core/types"
-	"runtime/debug"
-	"sync"
-	"time"
 )
 
-type PolyInfo struct {
-	sdk          *PolySDK
-	latestHeight uint64
+type polyNode struct {
+	sdk *PolySDK
 }
 
-func NewPolyInfo(url string) *PolyInfo {
-	sdk := NewPolySDK(url)
-	return &PolyInfo{
-		sdk:          sdk,
-		latestHeight: 0,
-	}
+func (n *polyNode) Height(ctx context.Context) (uint64, error) {
+	return n.sdk.GetCurrentBlockHeight(ctx)
 }
 
-type PolySDKPro struct {
-	infos         map[string]*PolyInfo
-	selectionSlot uint64
-	id            uint64
-	mutex         sync.Mutex
+// IsNodeError returns true for transport errors, others are answered by a working node.
+func (n *polyNode) IsNodeError(err error) bool {
+	return isTransportError(err)
 }
 
-func NewPolySDKPro(urls []string, slot uint64, id uint64) *PolySDKPro {
-	infos := make(map[string]*PolyInfo, len(urls))
-	for _, url := range urls {
-		infos[url] = NewPolyInfo(url)
-	}
-	pro := &PolySDKPro{infos: infos, selectionSlot: slot, id: id}
-	pro.selection()
-	go pro.NodeSelection()
-	return pro
-}
+func (n *polyNode) Close() {}
 
-func (pro *PolySDKPro) NodeSelection() {
-	for {
-		pro.nodeSelection()
-	}
+type PolySDKPro struct {
+	pool *Pool
 }
 
-func (pro *PolySDKPro) nodeSelection() {
-	defer func() {
-		if r := recover(); r != nil {
-			logs.Error("node selection, recover info: %s", string(debug.Stack()))
-		}
-	}()
-	logs.Debug("node selection of chain : %d......", pro.id)
-	ticker := time.NewTicker(time.Second * time.Duration(pro.selectionSlot))
-	for {
-		select {
-		case <-ticker.C:
-			pro.selection()
-		}
+func NewPolySDKPro(urls []string, slot uint64, id uint64) *PolySDKPro {
+	nodes := make(map[string]PoolNode, len(urls))
+	for _, url := range urls {
+		nodes[url] = &polyNode{sdk: NewPolySDK(url)}
 	}
+	return &PolySDKPro{pool: NewPool(nodes, slot, id)}
 }
 
-func (pro *PolySDKPro) selection() {
-	for url, info := range pro.infos {
-		height, err := info.sdk.GetCurrentBlockHeight()
-		if err != nil {
-			logs.Error("get current block height err: %v, url: %s", err, url)
-		}
-		pro.mutex.Lock()
-		info.latestHeight = height
-		pro.mutex.Unlock()
-	}
+// Close stops the node selection goroutine.
+func (pro *PolySDKPro) Close() {
+	pro.pool.Close()
 }
 
-func (pro *PolySDKPro) GetLatest() *PolyInfo {
-	pro.mutex.Lock()
-	defer func() {
-		pro.mutex.Unlock()
-	}()
-	height := uint64(0)
-	var latestInfo *PolyInfo = nil
-	for _, info := range pro.infos {
-		if info != nil && info.latestHeight > height {
-			height = info.latestHeight
-			latestInfo = info
-		}
-	}
-	return latestInfo
+func (pro *PolySDKPro) call(ctx context.Context, fn func(sdk *PolySDK) error) error {
+	return pro.pool.Call(ctx, func(node PoolNode) error {
+		return fn(node.(*polyNode).sdk)
+	})
 }
 
 func (pro *PolySDKPro) GetCurrentBlockHeight() (uint64, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return 0, fmt.Errorf("all node is not working")
-	}
-	return info.latestHeight, nil
+	return pro.pool.LatestHeight()
 }
 
-func (pro *PolySDKPro) GetBlockByHeight(height uint64) (*types.Block, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, fmt.Errorf("all node is not working")
-	}
-	for info != nil {
-		block, err := info.sdk.GetBlockByHeight(height)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return block, nil
-		}
-	}
-	return nil, fmt.Errorf("all node is not working")
+func (pro *PolySDKPro) GetBlockByHeight(ctx context.Context, height uint64) (block *types.Block, err error) {
+	err = pro.call(ctx, func(sdk *PolySDK) error {
+		block, err = sdk.GetBlockByHeight(ctx, height)
+		return err
+	})
+	return
 }
 
-func (pro *PolySDKPro) GetSmartContractEventByBlock(height uint64) ([]*common.SmartContactEvent, error) {
-	info := pro.GetLatest()
-	if info == nil {
-		return nil, fmt.Errorf("all node is not working")
-	}
-	for info != nil {
-		event, err := info.sdk.GetSmartContractEventByBlock(height)
-		if err != nil {
-			info.latestHeight = 0
-			info = pro.GetLatest()
-		} else {
-			return event, nil
-		}
-	}
-	return nil, fmt.Errorf("all node is not working")
+func (pro *PolySDKPro) GetSmartContractEventByBlock(ctx context.Context, height uint64) (event []*common.SmartContactEvent, err error) {
+	err = pro.call(ctx, func(sdk *PolySDK) error {
+		event, err = sdk.GetSmartContractEventByBlock(ctx, height)
+		return err
+	})
+	return
 }
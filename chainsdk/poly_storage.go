@@ -0,0 +1,185 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainsdk
+
+import (
+	"fmt"
+
+	"github.com/polynetwork/poly/common"
+	nm "github.com/polynetwork/poly/native/service/governance/node_manager"
+	scm "github.com/polynetwork/poly/native/service/governance/side_chain_manager"
+	hscom "github.com/polynetwork/poly/native/service/header_sync/common"
+	polyutils "github.com/polynetwork/poly/native/service/utils"
+)
+
+// SideChainStatus denotes side chain state in poly side chain manager contract,
+// the pointer fields are nil if there is no such record.
+type SideChainStatus struct {
+	Registered      *scm.SideChain
+	RegisterRequest *scm.SideChain
+	UpdateRequest   *scm.SideChain
+	QuitRequested   bool
+}
+
+// HeaderSyncStatus denotes side chain state in poly header sync contract.
+type HeaderSyncStatus struct {
+	GenesisSynced bool
+	Height        uint64
+}
+
+// GetSideChain read side chain registration from poly side chain manager contract storage,
+// and return nil if the side chain is not registered.
+func (s *PolySDK) GetSideChain(chainID uint64) (*scm.SideChain, error) {
+	return s.getSideChainStorage(scm.SIDE_CHAIN, chainID)
+}
+
+// GetSideChainApply returns the pending register request of side chain, or nil if not exist.
+func (s *PolySDK) GetSideChainApply(chainID uint64) (*scm.SideChain, error) {
+	return s.getSideChainStorage(scm.SIDE_CHAIN_APPLY, chainID)
+}
+
+// GetUpdateSideChainRequest returns the pending update request of side chain, or nil if not exist.
+func (s *PolySDK) GetUpdateSideChainRequest(chainID uint64) (*scm.SideChain, error) {
+	return s.getSideChainStorage(scm.UPDATE_SIDE_CHAIN_REQUEST, chainID)
+}
+
+func (s *PolySDK) IsQuitSideChainRequested(chainID uint64) (bool, error) {
+	raw, err := s.getStorage(polyutils.SideChainManagerContractAddress, scm.QUIT_SIDE_CHAIN_REQUEST, chainID)
+	if err != nil {
+		return false, err
+	}
+	return len(raw) > 0, nil
+}
+
+func (s *PolySDK) GetSideChainStatus(chainID uint64) (*SideChainStatus, error) {
+	var (
+		status = new(SideChainStatus)
+		err    error
+	)
+	if status.Registered, err = s.GetSideChain(chainID); err != nil {
+		return nil, err
+	}
+	if status.RegisterRequest, err = s.GetSideChainApply(chainID); err != nil {
+		return nil, err
+	}
+	if status.UpdateRequest, err = s.GetUpdateSideChainRequest(chainID); err != nil {
+		return nil, err
+	}
+	if status.QuitRequested, err = s.IsQuitSideChainRequested(chainID); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// GetHeaderSyncStatus checks whether the side chain genesis header has been synced to poly.
+// ethereum like chains store genesis header and current header height, and cosmos like chains
+// only store the latest epoch switch info, so we check both of them.
+func (s *PolySDK) GetHeaderSyncStatus(chainID uint64) (*HeaderSyncStatus, error) {
+	contract := polyutils.HeaderSyncContractAddress
+	status := new(HeaderSyncStatus)
+
+	genesis, err := s.getStorage(contract, hscom.GENESIS_HEADER, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if len(genesis) > 0 {
+		status.GenesisSynced = true
+		raw, err := s.getStorage(contract, hscom.CURRENT_HEADER_HEIGHT, chainID)
+		if err != nil {
+			return nil, err
+		}
+		if len(raw) > 0 {
+			height, eof := common.NewZeroCopySource(raw).NextUint64()
+			if eof {
+				return nil, fmt.Errorf("failed to deserialize current header height of chain %d", chainID)
+			}
+			status.Height = height
+		}
+		return status, nil
+	}
+
+	epoch, err := s.getStorage(contract, hscom.EPOCH_SWITCH, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if len(epoch) > 0 {
+		status.GenesisSynced = true
+		height, eof := common.NewZeroCopySource(epoch).NextInt64()
+		if eof {
+			return nil, fmt.Errorf("failed to deserialize epoch switch height of chain %d", chainID)
+		}
+		status.Height = uint64(height)
+	}
+	return status, nil
+}
+
+func (s *PolySDK) getSideChainStorage(prefix string, chainID uint64) (*scm.SideChain, error) {
+	raw, err := s.getStorage(polyutils.SideChainManagerContractAddress, prefix, chainID)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	sideChain := new(scm.SideChain)
+	if err := sideChain.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("failed to deserialize %s of chain %d, err: %v", prefix, chainID, err)
+	}
+	return sideChain, nil
+}
+
+// GetGovernanceView returns the current view of poly node manager contract.
+func (s *PolySDK) GetGovernanceView() (*nm.GovernanceView, error) {
+	raw, err := s.sdk.GetStorage(polyutils.NodeManagerContractAddress.ToHexString(), []byte(nm.GOVERNANCE_VIEW))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("governance view not exist")
+	}
+	view := new(nm.GovernanceView)
+	if err := view.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("failed to deserialize governance view, err: %v", err)
+	}
+	return view, nil
+}
+
+// GetPeerPoolMap returns all of the peers registered in poly node manager contract at the `view`.
+func (s *PolySDK) GetPeerPoolMap(view uint32) (*nm.PeerPoolMap, error) {
+	key := append([]byte(nm.PEER_POOL), polyutils.GetUint32Bytes(view)...)
+	raw, err := s.sdk.GetStorage(polyutils.NodeManagerContractAddress.ToHexString(), key)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("peer pool of view %d not exist", view)
+	}
+	peerPoolMap := new(nm.PeerPoolMap)
+	if err := peerPoolMap.Deserialization(common.NewZeroCopySource(raw)); err != nil {
+		return nil, fmt.Errorf("failed to deserialize peer pool of view %d, err: %v", view, err)
+	}
+	return peerPoolMap, nil
+}
+
+// getStorage read native contract storage with key `prefix + chainID`, an empty result denotes that
+// the storage not exist.
+func (s *PolySDK) getStorage(contract common.Address, prefix string, chainID uint64) ([]byte, error) {
+	key := append([]byte(prefix), polyutils.GetUint64Bytes(chainID)...)
+	return s.sdk.GetStorage(contract.ToHexString(), key)
+}
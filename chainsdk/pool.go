@@ -0,0 +1,355 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainsdk
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/astaxie/beego/logs"
+)
+
+var (
+	// DefaultLagTolerance denotes how many blocks a node can fall behind the best node and still be selected.
+	DefaultLagTolerance uint64 = 3
+	// DefaultBreakerThreshold denotes consecutive failures before a node is cut off.
+	DefaultBreakerThreshold = 3
+	// DefaultBreakerBackoff and DefaultBreakerMaxBackoff denote the exponential back-off of a cut off node.
+	DefaultBreakerBackoff    = 2 * time.Second
+	DefaultBreakerMaxBackoff = 2 * time.Minute
+)
+
+// healthDecay is the weight of the latest sample in latency and error rate moving average.
+const healthDecay = 0.3
+
+// PoolNode is the chain specific adapter of a node managed by Pool.
+type PoolNode interface {
+	// Height probes the latest block height of the node.
+	Height(ctx context.Context) (uint64, error)
+	// IsNodeError reports whether the error means the node is not working, errors answered by a
+	// working node (not found, execution reverted...) should not fail over to other nodes.
+	IsNodeError(err error) bool
+	// Close releases the connections of the node.
+	Close()
+}
+
+type poolNode struct {
+	url  string
+	node PoolNode
+
+	// states below are protected by Pool.mutex
+	latestHeight uint64
+	latency      time.Duration
+	errorRate    float64
+	failures     int
+	openUntil    time.Time
+}
+
+// score denotes the cost of selecting this node, node with lower score is preferred.
+func (n *poolNode) score() float64 {
+	latency := float64(n.latency) / float64(time.Millisecond)
+	return (latency + 1) * (1 + 10*n.errorRate)
+}
+
+func (n *poolNode) available(now time.Time) bool {
+	return n.latestHeight > 0 && !now.Before(n.openUntil)
+}
+
+// Pool manages several nodes of the same chain. it probes heights of the nodes periodically, and
+// selects the healthiest node within lag tolerance of the best node for each call, nodes failed
+// continuously are cut off with exponential back-off until a probe succeeds again.
+type Pool struct {
+	nodes         []*poolNode
+	selectionSlot uint64
+	id            uint64
+	chain         string
+	mutex         sync.Mutex
+
+	lagTolerance     uint64
+	breakerThreshold int
+	breakerBackoff   time.Duration
+	breakerMax       time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewPool creates the pool of chain `id` with nodes indexed by url, and starts node selection
+// every `slot` seconds.
+func NewPool(nodes map[string]PoolNode, slot uint64, id uint64) *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := &Pool{
+		nodes:            make([]*poolNode, 0, len(nodes)),
+		selectionSlot:    slot,
+		id:               id,
+		chain:            strconv.FormatUint(id, 10),
+		lagTolerance:     DefaultLagTolerance,
+		breakerThreshold: DefaultBreakerThreshold,
+		breakerBackoff:   DefaultBreakerBackoff,
+		breakerMax:       DefaultBreakerMaxBackoff,
+		ctx:              ctx,
+		cancel:           cancel,
+	}
+	for url, node := range nodes {
+		pool.nodes = append(pool.nodes, &poolNode{url: url, node: node})
+	}
+	pool.selection()
+	go pool.NodeSelection()
+	return pool
+}
+
+func (pool *Pool) NodeSelection() {
+	for pool.ctx.Err() == nil {
+		pool.nodeSelection()
+	}
+}
+
+// Close stops the node selection goroutine and closes connections of all nodes.
+func (pool *Pool) Close() {
+	pool.cancel()
+	for _, n := range pool.nodes {
+		n.node.Close()
+	}
+}
+
+func (pool *Pool) nodeSelection() {
+	defer func() {
+		if r := recover(); r != nil {
+			logs.Error("node selection, recover info: %s", string(debug.Stack()))
+		}
+	}()
+	logs.Debug("node selection of chain : %d......", pool.id)
+	ticker := time.NewTicker(time.Second * time.Duration(pool.selectionSlot))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pool.selection()
+		case <-pool.ctx.Done():
+			logs.Debug("node selection of chain : %d stopped", pool.id)
+			return
+		}
+	}
+}
+
+// selection probes every node which is not cut off, nodes whose back-off expired are probed again
+// to check whether they are recovered.
+func (pool *Pool) selection() {
+	now := time.Now()
+	for _, n := range pool.nodes {
+		pool.mutex.Lock()
+		skip := now.Before(n.openUntil)
+		pool.mutex.Unlock()
+		if skip {
+			continue
+		}
+
+		start := time.Now()
+		height, err := n.node.Height(pool.ctx)
+		if pool.ctx.Err() != nil {
+			return
+		}
+		if err == nil && (height == 0 || height == math.MaxUint64) {
+			err = fmt.Errorf("invalid block height %d", height)
+		}
+		if err != nil {
+			logs.Error("get current block height err: %v, url: %s", err, n.url)
+			pool.markFailure(n, time.Since(start))
+			continue
+		}
+		pool.markSuccess(n, time.Since(start), height)
+	}
+	pool.updateMetrics()
+}
+
+// updateMetrics exports the height, lag and availability of nodes.
+func (pool *Pool) updateMetrics() {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	now := time.Now()
+	best := uint64(0)
+	for _, n := range pool.nodes {
+		if n.available(now) && n.latestHeight > best {
+			best = n.latestHeight
+		}
+	}
+	for _, n := range pool.nodes {
+		lag := uint64(0)
+		if best > n.latestHeight {
+			lag = best - n.latestHeight
+		}
+		up := 0.0
+		if n.available(now) {
+			up = 1
+		}
+		poolNodeHeight.With(pool.chain, n.url).Set(float64(n.latestHeight))
+		poolNodeLag.With(pool.chain, n.url).Set(float64(lag))
+		poolNodeUp.With(pool.chain, n.url).Set(up)
+	}
+}
+
+func (pool *Pool) markSuccess(n *poolNode, latency time.Duration, height uint64) {
+	poolRequests.With(pool.chain, n.url).Inc()
+	poolLatency.With(pool.chain, n.url).Observe(latency.Seconds())
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if n.latency == 0 {
+		n.latency = latency
+	} else {
+		n.latency = time.Duration(healthDecay*float64(latency) + (1-healthDecay)*float64(n.latency))
+	}
+	n.errorRate = (1 - healthDecay) * n.errorRate
+	n.failures = 0
+	n.openUntil = time.Time{}
+	if height > 0 {
+		n.latestHeight = height
+	}
+}
+
+// markFailure records node failure, and cut off the node with exponential back-off if it
+// failed continuously.
+func (pool *Pool) markFailure(n *poolNode, latency time.Duration) {
+	poolRequests.With(pool.chain, n.url).Inc()
+	poolErrors.With(pool.chain, n.url).Inc()
+	poolLatency.With(pool.chain, n.url).Observe(latency.Seconds())
+
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	n.errorRate = healthDecay + (1-healthDecay)*n.errorRate
+	n.failures++
+	if n.failures < pool.breakerThreshold {
+		return
+	}
+	backoff := pool.breakerBackoff
+	for i := pool.breakerThreshold; i < n.failures && backoff < pool.breakerMax; i++ {
+		backoff *= 2
+	}
+	if backoff > pool.breakerMax {
+		backoff = pool.breakerMax
+	}
+	n.openUntil = time.Now().Add(backoff)
+	logs.Warn("node %s of chain %d failed %d times, cut off for %s", n.url, pool.id, n.failures, backoff)
+}
+
+// selectNode returns the healthiest node among nodes which are not cut off and not lag behind the
+// best node too much, nodes in `exclude` are ignored.
+func (pool *Pool) selectNode(exclude map[*poolNode]bool) *poolNode {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	now := time.Now()
+	best := uint64(0)
+	for _, n := range pool.nodes {
+		if n.available(now) && !exclude[n] && n.latestHeight > best {
+			best = n.latestHeight
+		}
+	}
+	if best == 0 {
+		return nil
+	}
+
+	var selected *poolNode
+	for _, n := range pool.nodes {
+		if !n.available(now) || exclude[n] || n.latestHeight+pool.lagTolerance < best {
+			continue
+		}
+		if selected == nil || n.score() < selected.score() {
+			selected = n
+		}
+	}
+	return selected
+}
+
+// Latest returns the node which would be selected for the next call, nil if all node is not working.
+func (pool *Pool) Latest() PoolNode {
+	n := pool.selectNode(nil)
+	if n == nil {
+		return nil
+	}
+	return n.node
+}
+
+// LatestHeight returns the best height among working nodes, the node selected for calls may fall
+// behind it within lag tolerance.
+func (pool *Pool) LatestHeight() (uint64, error) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	now := time.Now()
+	best := uint64(0)
+	for _, n := range pool.nodes {
+		if n.available(now) && n.latestHeight > best {
+			best = n.latestHeight
+		}
+	}
+	if best == 0 {
+		return 0, fmt.Errorf("all node is not working")
+	}
+	return best, nil
+}
+
+// SelectedHeight returns the latest height of the node which would be selected for the next call.
+func (pool *Pool) SelectedHeight() (uint64, error) {
+	n := pool.selectNode(nil)
+	if n == nil {
+		return 0, fmt.Errorf("all node is not working")
+	}
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+	return n.latestHeight, nil
+}
+
+// Call invokes `fn` with the selected node, and fail over to the next healthy node if the node
+// is not working. errors answered by the node itself and errors caused by `ctx` are returned directly.
+func (pool *Pool) Call(ctx context.Context, fn func(node PoolNode) error) error {
+	tried := make(map[*poolNode]bool)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n := pool.selectNode(tried)
+		if n == nil {
+			return fmt.Errorf("all node is not working")
+		}
+		if len(tried) > 0 {
+			poolFailovers.With(pool.chain).Inc()
+		}
+
+		start := time.Now()
+		err := fn(n.node)
+		if err != nil && ctx.Err() != nil {
+			return err
+		}
+		if err == nil || !n.node.IsNodeError(err) {
+			pool.markSuccess(n, time.Since(start), 0)
+			return err
+		}
+		logs.Debug("node %s of chain %d failed, err: %v", n.url, pool.id, err)
+		pool.markFailure(n, time.Since(start))
+		tried[n] = true
+	}
+}
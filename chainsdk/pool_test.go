@@ -0,0 +1,264 @@
+package chainsdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var errAnswered = errors.New("answered by node")
+
+// fakeNode is an in memory PoolNode whose height and health can be changed in tests.
+type fakeNode struct {
+	height  uint64
+	failing int32
+	calls   int64
+	closed  int32
+}
+
+func (n *fakeNode) Height(ctx context.Context) (uint64, error) {
+	atomic.AddInt64(&n.calls, 1)
+	if atomic.LoadInt32(&n.failing) == 1 {
+		return 0, fmt.Errorf("node is down")
+	}
+	return atomic.LoadUint64(&n.height), nil
+}
+
+func (n *fakeNode) IsNodeError(err error) bool {
+	return err != errAnswered
+}
+
+func (n *fakeNode) Close() {
+	atomic.StoreInt32(&n.closed, 1)
+}
+
+func (n *fakeNode) call() error {
+	if atomic.LoadInt32(&n.failing) == 1 {
+		return fmt.Errorf("node is down")
+	}
+	return nil
+}
+
+func (n *fakeNode) setFailing(failing bool) {
+	if failing {
+		atomic.StoreInt32(&n.failing, 1)
+	} else {
+		atomic.StoreInt32(&n.failing, 0)
+	}
+}
+
+func newTestPool(t *testing.T, nodes map[string]*fakeNode) *Pool {
+	poolNodes := make(map[string]PoolNode, len(nodes))
+	for url, node := range nodes {
+		poolNodes[url] = node
+	}
+	pool := NewPool(poolNodes, 3600, 2)
+	pool.breakerBackoff = 50 * time.Millisecond
+	pool.breakerMax = 200 * time.Millisecond
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func TestPool_LatestHeight(t *testing.T) {
+	a := &fakeNode{height: 100}
+	b := &fakeNode{height: 100 - DefaultLagTolerance}
+	pool := newTestPool(t, map[string]*fakeNode{"a": a, "b": b})
+
+	// the best node is slower, the node within lag tolerance is selected
+	pool.mutex.Lock()
+	for _, n := range pool.nodes {
+		if n.url == "a" {
+			n.latency = time.Second
+		} else {
+			n.latency = time.Millisecond
+		}
+	}
+	pool.mutex.Unlock()
+	if url := selectedUrl(pool); url != "b" {
+		t.Fatalf("faster node within lag tolerance should be selected, got %s", url)
+	}
+	if height, err := pool.LatestHeight(); err != nil || height != 100 {
+		t.Fatalf("latest height expected the best height 100, got %d, err: %v", height, err)
+	}
+	if height, err := pool.SelectedHeight(); err != nil || height != b.height {
+		t.Fatalf("selected height expected %d, got %d, err: %v", b.height, height, err)
+	}
+}
+
+func TestPool_LagTolerance(t *testing.T) {
+	a := &fakeNode{height: 100}
+	b := &fakeNode{height: 100 - DefaultLagTolerance - 1}
+	pool := newTestPool(t, map[string]*fakeNode{"a": a, "b": b})
+
+	if url := selectedUrl(pool); url != "a" {
+		t.Fatalf("lagging node should not be selected, got %s", url)
+	}
+	height, err := pool.LatestHeight()
+	if err != nil || height != 100 {
+		t.Fatalf("latest height expected 100, got %d, err: %v", height, err)
+	}
+
+	a.setFailing(true)
+	if err := pool.Call(context.Background(), func(node PoolNode) error {
+		return node.(*fakeNode).call()
+	}); err != nil {
+		t.Fatalf("lagging node should be used when the best node failed, err: %v", err)
+	}
+}
+
+func TestPool_CircuitBreaker(t *testing.T) {
+	a := &fakeNode{height: 100}
+	b := &fakeNode{height: 100}
+	pool := newTestPool(t, map[string]*fakeNode{"a": a, "b": b})
+
+	a.setFailing(true)
+	for i := 0; i < DefaultBreakerThreshold; i++ {
+		pool.selection()
+	}
+	for i := 0; i < 10; i++ {
+		if url := selectedUrl(pool); url != "b" {
+			t.Fatalf("broken node should be cut off, got %s", url)
+		}
+	}
+
+	// cut off node is not probed until back-off expires
+	calls := atomic.LoadInt64(&a.calls)
+	pool.selection()
+	if atomic.LoadInt64(&a.calls) != calls {
+		t.Fatalf("cut off node should not be probed")
+	}
+
+	// back-off grows exponentially and is limited by the max back-off
+	time.Sleep(pool.breakerBackoff)
+	pool.selection()
+	pool.mutex.Lock()
+	backoff := time.Until(pool.nodes[0].openUntil)
+	if pool.nodes[0].url != "a" {
+		backoff = time.Until(pool.nodes[1].openUntil)
+	}
+	pool.mutex.Unlock()
+	if backoff <= pool.breakerBackoff || backoff > pool.breakerMax {
+		t.Fatalf("back-off should be doubled, got %s", backoff)
+	}
+
+	// half open probe after back-off brings the recovered node back
+	a.setFailing(false)
+	b.setFailing(true)
+	time.Sleep(pool.breakerMax)
+	for i := 0; i < DefaultBreakerThreshold; i++ {
+		pool.selection()
+	}
+	if url := selectedUrl(pool); url != "a" {
+		t.Fatalf("recovered node should be selected, got %s", url)
+	}
+}
+
+func TestPool_Call(t *testing.T) {
+	a := &fakeNode{height: 100}
+	b := &fakeNode{height: 100}
+	pool := newTestPool(t, map[string]*fakeNode{"a": a, "b": b})
+
+	// errors answered by the node are returned without fail over
+	tried := 0
+	err := pool.Call(context.Background(), func(node PoolNode) error {
+		tried++
+		return errAnswered
+	})
+	if err != errAnswered || tried != 1 {
+		t.Fatalf("answered error should be returned directly, tried %d, err: %v", tried, err)
+	}
+
+	// node errors fail over to every healthy node once
+	tried = 0
+	err = pool.Call(context.Background(), func(node PoolNode) error {
+		tried++
+		return fmt.Errorf("node is down")
+	})
+	if err == nil || tried != 2 {
+		t.Fatalf("call should try all nodes, tried %d, err: %v", tried, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := pool.Call(ctx, func(node PoolNode) error { return nil }); err != context.Canceled {
+		t.Fatalf("call should fail with cancelled context, err: %v", err)
+	}
+}
+
+func TestPool_Concurrent(t *testing.T) {
+	a := &fakeNode{height: 100}
+	b := &fakeNode{height: 100}
+	c := &fakeNode{height: 99}
+	pool := newTestPool(t, map[string]*fakeNode{"a": a, "b": b, "c": c})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				switch j % 3 {
+				case 0:
+					pool.selection()
+				case 1:
+					pool.Call(context.Background(), func(node PoolNode) error {
+						return node.(*fakeNode).call()
+					})
+				default:
+					a.setFailing(j%2 == 0)
+					pool.LatestHeight()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestPool_Close(t *testing.T) {
+	a := &fakeNode{height: 100}
+	pool := newTestPool(t, map[string]*fakeNode{"a": a})
+
+	done := make(chan struct{})
+	go func() {
+		pool.NodeSelection()
+		close(done)
+	}()
+	pool.Close()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("node selection should be stopped")
+	}
+	if atomic.LoadInt32(&a.closed) != 1 {
+		t.Fatalf("nodes should be closed")
+	}
+}
+
+func TestPool_Metrics(t *testing.T) {
+	a := &fakeNode{height: 100}
+	b := &fakeNode{height: 98}
+	pool := newTestPool(t, map[string]*fakeNode{"a": a, "b": b})
+
+	if lag := poolNodeLag.With(pool.chain, "b").Value(); lag != 2 {
+		t.Fatalf("lag of node b expected 2, got %v", lag)
+	}
+	requests := poolRequests.With(pool.chain, "a").Value()
+	errs := poolErrors.With(pool.chain, "a").Value()
+	failovers := poolFailovers.With(pool.chain).Value()
+
+	a.setFailing(true)
+	b.setFailing(true)
+	pool.Call(context.Background(), func(node PoolNode) error {
+		return node.(*fakeNode).call()
+	})
+	if poolRequests.With(pool.chain, "a").Value() != requests+1 || poolErrors.With(pool.chain, "a").Value() != errs+1 {
+		t.Fatalf("request and error of node a should be counted")
+	}
+	if poolFailovers.With(pool.chain).Value() != failovers+1 {
+		t.Fatalf("failover should be counted")
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2020 The poly network Authors
+ * This file is part of The poly network library.
+ *
+ * The  poly network  is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The  poly network  is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The poly network .  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chainsdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// callWithContext runs `fn` which is not aware of context, and returns as soon as the context is
+// done. the running call is abandoned and it's result is dropped in that case, so `fn` must not
+// write to variables of caller, the result is passed back through the return value instead.
+func callWithContext(ctx context.Context, fn func() (interface{}, error)) (interface{}, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	type result struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := fn()
+		done <- result{value: value, err: err}
+	}()
+	select {
+	case res := <-done:
+		return res.value, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// transportErrorMessages are the texts of transport errors, poly, ontology and neo sdks format errors
+// as text so the types are lost. an html page answered by a proxy in front of the node is also a
+// node failure, which fails json decoding.
+var transportErrorMessages = []string{
+	"http post request", "read rpc response body", "dial tcp", "connection refused", "connection reset",
+	"broken pipe", "no such host", "network is unreachable", "tls handshake", "timeout", "eof",
+	"bad gateway", "service unavailable", "internal server error",
+	"invalid character '<'", "jsonrpcresponse:<",
+}
+
+var httpServerErrorPattern = regexp.MustCompile(`status(?: code)?:? ?5\d\d`)
+
+// isTransportError reports whether err is caused by the connection to node or a failed node, e.g.
+// network errors, timeout and http 5xx. other errors are answered by a working node.
+func isTransportError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, text := range transportErrorMessages {
+		if strings.Contains(msg, text) {
+			return true
+		}
+	}
+	return httpServerErrorPattern.MatchString(msg)
+}
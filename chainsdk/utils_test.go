@@ -0,0 +1,220 @@
+package chainsdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ontio/ontology-go-sdk"
+)
+
+// slowStub answers json rpc of poly and ontology nodes, requests of slow methods are held until
+// released, so that the calls time out before the answer arrives.
+type slowStub struct {
+	server   *httptest.Server
+	slow     map[string]bool
+	released chan struct{}
+	held     int32
+	answered int32
+	once     sync.Once
+}
+
+func newSlowStub(t *testing.T, slow ...string) *slowStub {
+	stub := &slowStub{slow: make(map[string]bool), released: make(chan struct{})}
+	for _, method := range slow {
+		stub.slow[method] = true
+	}
+	stub.server = httptest.NewServer(http.HandlerFunc(stub.serve))
+	t.Cleanup(func() {
+		stub.release()
+		stub.server.Close()
+	})
+	return stub
+}
+
+func (stub *slowStub) serve(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	json.Unmarshal(body, &req)
+	if stub.slow[req.Method] {
+		atomic.AddInt32(&stub.held, 1)
+		<-stub.released
+		defer atomic.AddInt32(&stub.answered, 1)
+	}
+	fmt.Fprintf(w, `{"desc":"SUCCESS","error":0,"id":%s,"jsonrpc":"2.0","result":10}`, req.ID)
+}
+
+// release answers the held requests and waits until they're served, the abandoned calls get the
+// answer after that.
+func (stub *slowStub) release() {
+	stub.once.Do(func() { close(stub.released) })
+	for atomic.LoadInt32(&stub.answered) < atomic.LoadInt32(&stub.held) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(50 * time.Millisecond)
+}
+
+func timeoutContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func TestCallWithContext(t *testing.T) {
+	res, err := callWithContext(context.Background(), func() (interface{}, error) {
+		return 1, nil
+	})
+	if err != nil || res.(int) != 1 {
+		t.Fatalf("unexpected result %v, err: %v", res, err)
+	}
+
+	released := make(chan struct{})
+	res, err = callWithContext(timeoutContext(t), func() (interface{}, error) {
+		<-released
+		return 1, nil
+	})
+	close(released)
+	if err != context.DeadlineExceeded || res != nil {
+		t.Fatalf("timed out call should return zero value, got %v, err: %v", res, err)
+	}
+}
+
+func TestPolySDK_Timeout(t *testing.T) {
+	stub := newSlowStub(t, "getblockcount", "getblock", "getsmartcodeevent")
+	sdk := NewPolySDK(stub.server.URL)
+
+	height, err := sdk.GetCurrentBlockHeight(timeoutContext(t))
+	if err != context.DeadlineExceeded || height != 0 {
+		t.Fatalf("unexpected height %d, err: %v", height, err)
+	}
+	block, err := sdk.GetBlockByHeight(timeoutContext(t), 1)
+	if err != context.DeadlineExceeded || block != nil {
+		t.Fatalf("unexpected block %v, err: %v", block, err)
+	}
+	events, err := sdk.GetSmartContractEventByBlock(timeoutContext(t), 1)
+	if err != context.DeadlineExceeded || events != nil {
+		t.Fatalf("unexpected events %v, err: %v", events, err)
+	}
+
+	stub.release()
+	if height != 0 || block != nil || events != nil {
+		t.Fatalf("results are changed after the calls returned")
+	}
+}
+
+func TestOntologySdkPro_Timeout(t *testing.T) {
+	stub := newSlowStub(t, "getblock", "getsmartcodeevent")
+	pro := NewOntologySdkPro([]string{stub.server.URL}, 3600, 3)
+	t.Cleanup(pro.Close)
+
+	block, err := pro.GetBlockByHeight(timeoutContext(t), 1)
+	if err != context.DeadlineExceeded || block != nil {
+		t.Fatalf("unexpected block %v, err: %v", block, err)
+	}
+	events, err := pro.GetSmartContractEventByBlock(timeoutContext(t), 1)
+	if err != context.DeadlineExceeded || events != nil {
+		t.Fatalf("unexpected events %v, err: %v", events, err)
+	}
+
+	slowNode := newSlowStub(t, "getblockcount")
+	sdk := ontology_go_sdk.NewOntologySdk()
+	sdk.NewRpcClient().SetAddress(slowNode.server.URL)
+	height, err := (&ontologyNode{sdk: sdk}).Height(timeoutContext(t))
+	if err != context.DeadlineExceeded || height != 0 {
+		t.Fatalf("unexpected height %d, err: %v", height, err)
+	}
+
+	stub.release()
+	slowNode.release()
+	if height != 0 || block != nil || events != nil {
+		t.Fatalf("results are changed after the calls returned")
+	}
+}
+
+// answerStub answers every request with status and body.
+func answerStub(t *testing.T, status int, body string) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// nodeErrorCases returns urls of a closed node, a node behind failed proxy and a working node which
+// answers the json rpc error `appError`, only the last is not a node error.
+func nodeErrorCases(t *testing.T, appError string) map[string]bool {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	return map[string]bool{
+		closed.URL: true,
+		answerStub(t, http.StatusBadGateway, "<html><body>502 Bad Gateway</body></html>"): true,
+		answerStub(t, http.StatusOK, appError):                                            false,
+	}
+}
+
+func TestPolyNode_IsNodeError(t *testing.T) {
+	appError := `{"desc":"UNKNOWN BLOCK","error":44001,"id":"1","jsonrpc":"2.0","result":""}`
+	for url, expect := range nodeErrorCases(t, appError) {
+		node := &polyNode{sdk: NewPolySDK(url)}
+		_, err := node.sdk.GetBlockByHeight(context.Background(), 1)
+		if err == nil || node.IsNodeError(err) != expect {
+			t.Fatalf("node error of %q expected %v", err, expect)
+		}
+	}
+}
+
+func TestOntologyNode_IsNodeError(t *testing.T) {
+	appError := `{"desc":"UNKNOWN BLOCK","error":44001,"id":"1","jsonrpc":"2.0","result":""}`
+	for url, expect := range nodeErrorCases(t, appError) {
+		sdk := ontology_go_sdk.NewOntologySdk()
+		sdk.NewRpcClient().SetAddress(url)
+		node := &ontologyNode{sdk: sdk}
+		_, err := sdk.GetBlockByHeight(1)
+		if err == nil || node.IsNodeError(err) != expect {
+			t.Fatalf("node error of %q expected %v", err, expect)
+		}
+	}
+}
+
+func TestNeoNode_IsNodeError(t *testing.T) {
+	appError := `{"jsonrpc":"2.0","id":1,"error":{"code":-100,"message":"Unknown block"}}`
+	for url, expect := range nodeErrorCases(t, appError) {
+		node := &neoNode{sdk: NewNeoSdk(url)}
+		_, err := node.sdk.GetBlockByIndex(context.Background(), 1)
+		if err == nil || node.IsNodeError(err) != expect {
+			t.Fatalf("node error of %q expected %v", err, expect)
+		}
+	}
+	if (&neoNode{}).IsNodeError(ErrTxRejected) {
+		t.Fatalf("rejected transaction is answered by a working node")
+	}
+}
+
+func TestIsTransportError(t *testing.T) {
+	cases := map[error]bool{
+		io.EOF:                         true,
+		context.DeadlineExceeded:       true,
+		&net.OpError{Op: "dial"}:       true,
+		fmt.Errorf("status code: 503"): true,
+		fmt.Errorf("JsonRpcResponse error code:42002 desc:INVALID PARAMS"): false,
+		fmt.Errorf("unknown transaction"):                                  false,
+	}
+	for err, expect := range cases {
+		if isTransportError(err) != expect {
+			t.Fatalf("transport error of %q expected %v", err, expect)
+		}
+	}
+}
@@ -10,9 +10,11 @@ import (
 )
 
 var (
+	// precision of MultiT, PrintUT and so on, it's 18 until Init called, use TokenAmount for tokens
+	// with different decimals.
 	_decimal int32 = 18
-	N1       *big.Int
-	_n1      decimal.Decimal
+	N1             = Pow10toBigInt(18)
+	_n1            = DecimalFromBigInt(N1)
 
 	EmptyDecimal = decimal.Zero
 	EmptyBig     = big.NewInt(0)
@@ -0,0 +1,182 @@
+package math
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"poly-bridge/utils/decimal"
+)
+
+// RoundingMode decides how the dropped digits are handled when token amount is rescaled to less decimals.
+type RoundingMode int
+
+const (
+	// RoundDown drops the digits, rounds toward zero.
+	RoundDown RoundingMode = iota
+	// RoundUp rounds away from zero if any digit dropped is not zero.
+	RoundUp
+	// RoundHalfUp rounds to nearest, and away from zero if it's equidistant.
+	RoundHalfUp
+	// RoundHalfEven rounds to nearest, and to the even one if it's equidistant.
+	RoundHalfEven
+)
+
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundDown:
+		return "down"
+	case RoundUp:
+		return "up"
+	case RoundHalfUp:
+		return "half up"
+	case RoundHalfEven:
+		return "half even"
+	}
+	return fmt.Sprintf("unknown(%d)", int(m))
+}
+
+// round rounds d to integer with mode.
+func (m RoundingMode) round(d decimal.Decimal) (decimal.Decimal, error) {
+	switch m {
+	case RoundDown:
+		return d.Truncate(0), nil
+	case RoundUp:
+		if d.Sign() < 0 {
+			return d.Floor(), nil
+		}
+		return d.Ceil(), nil
+	case RoundHalfUp:
+		return d.Round(0), nil
+	case RoundHalfEven:
+		return d.RoundBank(0), nil
+	}
+	return d, fmt.Errorf("unknown rounding mode %d", int(m))
+}
+
+var tokenAmountPattern = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)$`)
+
+// TokenAmount is amount of token with its decimals, the raw amount is in minimal unit of token, e.g: wei,
+// and the display amount is raw amount / 10^decimals.
+type TokenAmount struct {
+	raw      *big.Int
+	decimals uint8
+}
+
+// NewTokenAmount returns token amount of raw amount in minimal unit, nil is zero.
+func NewTokenAmount(raw *big.Int, decimals uint8) TokenAmount {
+	if raw == nil {
+		return TokenAmount{raw: new(big.Int), decimals: decimals}
+	}
+	return TokenAmount{raw: new(big.Int).Set(raw), decimals: decimals}
+}
+
+// TokenAmountFromDecimal returns token amount of display amount, which must not have more digits
+// than decimals.
+func TokenAmountFromDecimal(amount decimal.Decimal, decimals uint8) (TokenAmount, error) {
+	raw := amount.Shift(int32(decimals))
+	if !raw.IsInteger() {
+		return TokenAmount{}, fmt.Errorf("amount %s has more than %d decimals", amount.String(), decimals)
+	}
+	return TokenAmount{raw: raw.BigInt(), decimals: decimals}, nil
+}
+
+// ParseTokenAmount parses display amount, e.g: 1.5, exponent is not allowed and the raw amount must be
+// in uint256.
+func ParseTokenAmount(value string, decimals uint8) (TokenAmount, error) {
+	value = strings.TrimSpace(value)
+	if !tokenAmountPattern.MatchString(value) {
+		return TokenAmount{}, fmt.Errorf("invalid amount %q", value)
+	}
+	amount, err := decimal.NewFromString(value)
+	if err != nil {
+		return TokenAmount{}, fmt.Errorf("invalid amount %q, %v", value, err)
+	}
+	a, err := TokenAmountFromDecimal(amount, decimals)
+	if err != nil {
+		return TokenAmount{}, err
+	}
+	if new(big.Int).Abs(a.raw).Cmp(MaxUint256) > 0 {
+		return TokenAmount{}, fmt.Errorf("amount %q overflows uint256", value)
+	}
+	return a, nil
+}
+
+func (a TokenAmount) Decimals() uint8 {
+	return a.decimals
+}
+
+// Raw returns a copy of the raw amount in minimal unit.
+func (a TokenAmount) Raw() *big.Int {
+	if a.raw == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(a.raw)
+}
+
+// Decimal returns the display amount.
+func (a TokenAmount) Decimal() decimal.Decimal {
+	return decimal.NewFromBigInt(a.Raw(), -int32(a.decimals))
+}
+
+// String returns the display amount without trailing zeros, e.g: 1.5
+func (a TokenAmount) String() string {
+	return a.Decimal().String()
+}
+
+// StringFixed returns the display amount with all decimals, e.g: 1.500000
+func (a TokenAmount) StringFixed() string {
+	return a.Decimal().StringFixed(int32(a.decimals))
+}
+
+func (a TokenAmount) Sign() int {
+	return a.Raw().Sign()
+}
+
+func (a TokenAmount) IsZero() bool {
+	return a.Sign() == 0
+}
+
+// Cmp compares display amounts, the decimals may be different.
+func (a TokenAmount) Cmp(b TokenAmount) int {
+	return a.Decimal().Cmp(b.Decimal())
+}
+
+// Add returns a + b, they must have the same decimals.
+func (a TokenAmount) Add(b TokenAmount) (TokenAmount, error) {
+	if a.decimals != b.decimals {
+		return TokenAmount{}, fmt.Errorf("add amounts of decimals %d and %d", a.decimals, b.decimals)
+	}
+	return TokenAmount{raw: new(big.Int).Add(a.Raw(), b.Raw()), decimals: a.decimals}, nil
+}
+
+// Sub returns a - b, they must have the same decimals.
+func (a TokenAmount) Sub(b TokenAmount) (TokenAmount, error) {
+	if a.decimals != b.decimals {
+		return TokenAmount{}, fmt.Errorf("sub amounts of decimals %d and %d", a.decimals, b.decimals)
+	}
+	return TokenAmount{raw: new(big.Int).Sub(a.Raw(), b.Raw()), decimals: a.decimals}, nil
+}
+
+// Rescale converts the amount to token with `decimals` keeping the display amount, the digits out of
+// decimals are rounded with mode.
+func (a TokenAmount) Rescale(decimals uint8, mode RoundingMode) (TokenAmount, error) {
+	raw, err := mode.round(decimal.NewFromBigInt(a.Raw(), int32(decimals)-int32(a.decimals)))
+	if err != nil {
+		return TokenAmount{}, err
+	}
+	return TokenAmount{raw: raw.BigInt(), decimals: decimals}, nil
+}
+
+// RescaleExact is Rescale which fails if any digit is dropped.
+func (a TokenAmount) RescaleExact(decimals uint8) (TokenAmount, error) {
+	b, err := a.Rescale(decimals, RoundDown)
+	if err != nil {
+		return TokenAmount{}, err
+	}
+	if b.Cmp(a) != 0 {
+		return TokenAmount{}, fmt.Errorf("amount %s has more than %d decimals", a.String(), decimals)
+	}
+	return b, nil
+}
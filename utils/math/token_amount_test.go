@@ -0,0 +1,198 @@
+package math
+
+import (
+	"math/big"
+	"testing"
+
+	"poly-bridge/utils/decimal"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTokenAmount(t *testing.T) {
+	cases := []struct {
+		value    string
+		decimals uint8
+		raw      string
+		ok       bool
+	}{
+		{"1", 18, "1000000000000000000", true},
+		{"1.5", 18, "1500000000000000000", true},
+		{"0.000000000000000001", 18, "1", true},
+		{"0.0000000000000000001", 18, "", false},
+		{"21000000", 8, "2100000000000000", true},
+		{"0.00000001", 8, "1", true},
+		{"0.000000001", 8, "", false},
+		{"0.000001", 6, "1", true},
+		{"0.0000010", 6, "1", true},
+		{"0.0000001", 6, "", false},
+		{" 100.25 ", 6, "100250000", true},
+		{".5", 6, "500000", true},
+		{"5.", 6, "5000000", true},
+		{"-1.5", 6, "-1500000", true},
+		{"0", 0, "0", true},
+		{"1.5", 0, "", false},
+		{"", 18, "", false},
+		{".", 18, "", false},
+		{"1e18", 0, "", false},
+		{"1,000", 6, "", false},
+		{"abc", 6, "", false},
+		{"1.2.3", 6, "", false},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639935", 0,
+			"115792089237316195423570985008687907853269984665640564039457584007913129639935", true},
+		{"115792089237316195423570985008687907853269984665640564039457584007913129639936", 0, "", false},
+		{"115792089237316195423570985008687907853269984665640564039457.584007913129639936", 18, "", false},
+	}
+	for _, c := range cases {
+		a, err := ParseTokenAmount(c.value, c.decimals)
+		if !c.ok {
+			assert.Error(t, err, c.value)
+			continue
+		}
+		if assert.NoError(t, err, c.value) {
+			assert.Equal(t, c.raw, a.Raw().String(), c.value)
+			assert.Equal(t, c.decimals, a.Decimals(), c.value)
+		}
+	}
+}
+
+func TestTokenAmount_String(t *testing.T) {
+	raw, _ := new(big.Int).SetString("1234500000000000000", 10)
+	a := NewTokenAmount(raw, 18)
+	assert.Equal(t, "1.2345", a.String())
+	assert.Equal(t, "1.234500000000000000", a.StringFixed())
+
+	assert.Equal(t, "0.00000001", NewTokenAmount(big.NewInt(1), 8).String())
+	assert.Equal(t, "0.000001", NewTokenAmount(big.NewInt(1), 6).String())
+	assert.Equal(t, "0.000000", NewTokenAmount(nil, 6).StringFixed())
+	assert.True(t, NewTokenAmount(nil, 6).IsZero())
+	assert.True(t, TokenAmount{}.IsZero())
+
+	// round trip between raw and display amount is lossless
+	for _, decimals := range []uint8{18, 8, 6, 0} {
+		for _, value := range []string{"1", "123456789", "340282366920938463463374607431768211455"} {
+			raw, _ := new(big.Int).SetString(value, 10)
+			a := NewTokenAmount(raw, decimals)
+			b, err := ParseTokenAmount(a.String(), decimals)
+			assert.NoError(t, err)
+			assert.Equal(t, value, b.Raw().String())
+			b, err = ParseTokenAmount(a.StringFixed(), decimals)
+			assert.NoError(t, err)
+			assert.Equal(t, value, b.Raw().String())
+		}
+	}
+}
+
+func TestTokenAmount_Immutable(t *testing.T) {
+	raw := big.NewInt(100)
+	a := NewTokenAmount(raw, 6)
+	raw.SetInt64(1)
+	assert.Equal(t, "100", a.Raw().String())
+	a.Raw().SetInt64(2)
+	assert.Equal(t, "100", a.Raw().String())
+}
+
+func TestTokenAmount_Rescale(t *testing.T) {
+	cases := []struct {
+		value string
+		from  uint8
+		to    uint8
+		mode  RoundingMode
+		raw   string
+	}{
+		// 18 -> 6
+		{"1.0000005", 18, 6, RoundDown, "1000000"},
+		{"1.0000005", 18, 6, RoundUp, "1000001"},
+		{"1.0000005", 18, 6, RoundHalfUp, "1000001"},
+		{"1.0000005", 18, 6, RoundHalfEven, "1000000"},
+		{"1.0000015", 18, 6, RoundHalfEven, "1000002"},
+		{"1.000000499999999999", 18, 6, RoundHalfUp, "1000000"},
+		{"0.000000000000000001", 18, 6, RoundDown, "0"},
+		{"0.000000000000000001", 18, 6, RoundUp, "1"},
+		{"-1.0000005", 18, 6, RoundDown, "-1000000"},
+		{"-1.0000005", 18, 6, RoundUp, "-1000001"},
+		{"-1.0000005", 18, 6, RoundHalfUp, "-1000001"},
+		{"-1.0000005", 18, 6, RoundHalfEven, "-1000000"},
+		// 18 -> 8
+		{"0.123456789", 18, 8, RoundDown, "12345678"},
+		{"0.123456789", 18, 8, RoundHalfUp, "12345679"},
+		{"21000000", 18, 8, RoundDown, "2100000000000000"},
+		// 8 -> 6
+		{"0.00000099", 8, 6, RoundDown, "0"},
+		{"0.00000099", 8, 6, RoundHalfUp, "1"},
+		// up scale is always exact
+		{"1.000001", 6, 18, RoundDown, "1000001000000000000"},
+		{"0.00000001", 8, 18, RoundUp, "10000000000"},
+		{"1.5", 6, 6, RoundUp, "1500000"},
+	}
+	for _, c := range cases {
+		a, err := ParseTokenAmount(c.value, c.from)
+		if !assert.NoError(t, err, c.value) {
+			continue
+		}
+		b, err := a.Rescale(c.to, c.mode)
+		if assert.NoError(t, err, c.value) {
+			assert.Equal(t, c.raw, b.Raw().String(), "%s %d -> %d round %s", c.value, c.from, c.to, c.mode)
+			assert.Equal(t, c.to, b.Decimals())
+		}
+	}
+
+	_, err := NewTokenAmount(big.NewInt(1), 6).Rescale(18, RoundingMode(100))
+	assert.Error(t, err)
+}
+
+func TestTokenAmount_RescaleExact(t *testing.T) {
+	a, _ := ParseTokenAmount("1.5", 18)
+	b, err := a.RescaleExact(6)
+	assert.NoError(t, err)
+	assert.Equal(t, "1500000", b.Raw().String())
+
+	a, _ = ParseTokenAmount("1.0000005", 18)
+	_, err = a.RescaleExact(6)
+	assert.Error(t, err)
+
+	a, _ = ParseTokenAmount("0.00000001", 8)
+	_, err = a.RescaleExact(6)
+	assert.Error(t, err)
+}
+
+func TestTokenAmount_Cmp(t *testing.T) {
+	a, _ := ParseTokenAmount("1.5", 18)
+	b, _ := ParseTokenAmount("1.5", 6)
+	c, _ := ParseTokenAmount("1.50000001", 8)
+	assert.Equal(t, 0, a.Cmp(b))
+	assert.Equal(t, -1, b.Cmp(c))
+	assert.Equal(t, 1, c.Cmp(a))
+	assert.Equal(t, 0, TokenAmount{}.Cmp(NewTokenAmount(nil, 18)))
+}
+
+func TestTokenAmount_AddSub(t *testing.T) {
+	a, _ := ParseTokenAmount("1.5", 6)
+	b, _ := ParseTokenAmount("0.000001", 6)
+	sum, err := a.Add(b)
+	assert.NoError(t, err)
+	assert.Equal(t, "1.500001", sum.String())
+	diff, err := b.Sub(a)
+	assert.NoError(t, err)
+	assert.Equal(t, "-1.499999", diff.String())
+	assert.Equal(t, "1.5", a.String())
+
+	c, _ := ParseTokenAmount("1", 18)
+	_, err = a.Add(c)
+	assert.Error(t, err)
+	_, err = a.Sub(c)
+	assert.Error(t, err)
+}
+
+func TestTokenAmountFromDecimal(t *testing.T) {
+	a, err := TokenAmountFromDecimal(decimal.New(15, -1), 8)
+	assert.NoError(t, err)
+	assert.Equal(t, "150000000", a.Raw().String())
+	_, err = TokenAmountFromDecimal(decimal.New(15, -9), 8)
+	assert.Error(t, err)
+}
+
+func TestMultiTWithoutInit(t *testing.T) {
+	assert.Equal(t, "2000000000000000000", MultiT(2).String())
+	assert.Equal(t, uint64(2), PrintUT(MultiT(2)))
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	r := NewRegistry()
+	requests := r.NewCounterVec("rpc_requests_total", "Total rpc requests.", "chain", "node")
+	height := r.NewGaugeVec("node_height", "Latest height.", "node")
+	latency := r.NewHistogramVec("rpc_latency_seconds", "Rpc latency.", []float64{0.1, 1}, "node")
+	r.NewCounterVec("unused_total", "Never observed.")
+
+	requests.With("2", "http://b").Inc()
+	requests.With("2", "http://a").Add(2)
+	requests.With("2", "http://a").Add(-1)
+	height.With(`say "hi"`).Set(100)
+	latency.With("a").Observe(0.05)
+	latency.With("a").Observe(0.1)
+	latency.With("a").Observe(3)
+
+	buf := new(bytes.Buffer)
+	n, err := r.WriteTo(buf)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(buf.Len()), n)
+	expect := `# HELP node_height Latest height.
+# TYPE node_height gauge
+node_height{node="say \"hi\""} 100
+# HELP rpc_latency_seconds Rpc latency.
+# TYPE rpc_latency_seconds histogram
+rpc_latency_seconds_bucket{node="a",le="0.1"} 2
+rpc_latency_seconds_bucket{node="a",le="1"} 2
+rpc_latency_seconds_bucket{node="a",le="+Inf"} 3
+rpc_latency_seconds_sum{node="a"} 3.15
+rpc_latency_seconds_count{node="a"} 3
+# HELP rpc_requests_total Total rpc requests.
+# TYPE rpc_requests_total counter
+rpc_requests_total{chain="2",node="http://a"} 2
+rpc_requests_total{chain="2",node="http://b"} 1
+`
+	assert.Equal(t, expect, buf.String())
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	a := r.NewCounterVec("tx_total", "Transactions.", "status")
+	b := r.NewCounterVec("tx_total", "Transactions.", "status")
+	a.With("sent").Inc()
+	assert.Equal(t, float64(1), b.With("sent").Value())
+
+	assert.Panics(t, func() { r.NewGaugeVec("tx_total", "Transactions.", "status") })
+	assert.Panics(t, func() { a.With("sent", "extra") })
+	assert.Panics(t, func() { r.NewHistogramVec("bad_seconds", "Bad.", []float64{1, 0.1}) })
+}
+
+func TestRegistry_Concurrent(t *testing.T) {
+	r := NewRegistry()
+	counter := r.NewCounterVec("c_total", "C.", "node")
+	histogram := r.NewHistogramVec("h_seconds", "H.", nil, "node")
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				counter.With("a").Inc()
+				histogram.With("a").Observe(0.2)
+				r.WriteTo(ioutil.Discard)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, float64(800), counter.With("a").Value())
+	assert.Equal(t, uint64(800), histogram.With("a").Count())
+}
+
+func TestServe(t *testing.T) {
+	r := NewRegistry()
+	r.NewGaugeVec("up", "Up.").With().Set(1)
+
+	server, err := Serve("127.0.0.1:18546", r)
+	assert.NoError(t, err)
+	defer server.Close()
+
+	resp, err := http.Get("http://127.0.0.1:18546/metrics")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "# HELP up Up.\n# TYPE up gauge\nup 1\n", string(body))
+
+	_, err = Serve("256.0.0.1:0", r)
+	assert.Error(t, err)
+}